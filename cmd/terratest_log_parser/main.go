@@ -6,10 +6,33 @@
 //   |-> TEST_NAME.log
 //   |-> summary.log
 //   |-> report.xml
+//   |-> timing.json
 // where:
 // - `TEST_NAME.log` is a log for each test run that only includes the relevant logs for that test.
 // - `summary.log` is a summary of all the tests in the suite, including PASS/FAIL information.
 // - `report.xml` is the test summary in junit XML format to be consumed by a CI engine.
+// - `timing.json` contains the duration of each test, and of each stage it ran with `test_structure.RunTestStage`. The
+//   slowest tests and stages are also listed at the end of `summary.log`.
+//
+// With `--format html`, a self contained `report.html` is produced instead of (or, with `--format junit,html`, in
+// addition to) `report.xml`, showing the tests as a collapsible tree along with their logs.
+//
+// When the test suite is sharded across several machines, `--testlog` can be passed multiple times (or given a glob) to
+// merge the logs of all the shards into a single output directory. The summary log then contains the summary of each
+// shard under a `=== SHARD: name` header, and the reports record the shard each test ran in, named after its log file.
+//
+// The input can either be the plain text output of `go test -v` or the JSON output of `go test -json`, which is detected
+// automatically. The JSON output is preferred when available, as it attributes every line to the test that logged it.
+//
+// With `--follow`, the parser is meant to be fed the output of a test run that is still in progress (e.g. by piping
+// `go test` into it). In addition to the logs of each test, which are always written as the lines arrive, it then
+// rewrites `live_summary.log` every `--follow-interval` with the tests that are running, passed and failed so far, so
+// that long running suites can be monitored from the CI artifacts directory.
+//
+// The `flaky` subcommand takes the output directories of several previous runs, and reports the pass rate of each test
+// along with the tests that both passed and failed on the same commit, as `flakiness.txt` and `flakiness.json`:
+//
+//   terratest_log_parser flaky --outputdir flaky run-1@COMMIT run-2@COMMIT ...
 //
 // Certain tradeoffs were made in the decision to implement this functionality as a separate parsing command, as opposed
 // to being built into the logger module as part of `Logf`. Specifically, this implementation avoids the difficulties of
@@ -38,6 +61,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/gruntwork-io/go-commons/entrypoint"
 	"github.com/gruntwork-io/go-commons/errors"
@@ -49,21 +74,40 @@ import (
 
 var logger = logging.GetLogger("terratest_log_parser")
 
-const CUSTOM_USAGE_TEXT = `Usage: terratest_log_parser [--help] [--log-level=info] [--testlog=LOG_INPUT] [--outputdir=OUTPUT_DIR]
+const CUSTOM_USAGE_TEXT = `Usage: terratest_log_parser [--help] [--log-level=info] [--testlog=LOG_INPUT ...] [--outputdir=OUTPUT_DIR] [--format=junit] [--follow]
 
 A tool for parsing parallel terratest output to produce a test summary and to break out the interleaved logs by test for better debuggability.
 
 Options:
    --log-level LEVEL  Set the log level to LEVEL. Must be one of: [panic fatal error warning info debug]
                       (default: "info")
-   --testlog value    Path to file containing test log. If unset will use stdin.
+   --testlog value    Path to file containing test log (either go test -v or go test -json output). If unset will use stdin.
+                      Can be passed multiple times, or be a glob, to merge the logs of a sharded test suite.
    --outputdir value  Path to directory to output test output to. If unset will use the current directory.
+   --format value     Comma separated list of report formats to produce. Must be one of: [junit html]
+                      (default: "junit")
+   --follow           Rewrite live_summary.log in the output directory with the running, passed and failed tests while
+                      the test log is read. Use when piping a running go test into the parser.
+   --follow-interval value
+                      How often to rewrite live_summary.log in follow mode. (default: 10s)
    --help, -h         show help
+
+Commands:
+   flaky RUN_DIR[@COMMIT]...  Report the pass rate of each test across the output directories of previous runs, along
+                              with the tests that flipped between pass and fail on the same commit. Writes
+                              flakiness.txt and flakiness.json to --outputdir.
 `
 
 func run(cliContext *cli.Context) error {
-	filename := cliContext.String("testlog")
+	filenames, err := expandTestLogs(cliContext.StringSlice("testlog"))
+	if err != nil {
+		return err
+	}
 	outputDir := cliContext.String("outputdir")
+	formats := []parser.ReportFormat{}
+	for _, format := range strings.Split(cliContext.String("format"), ",") {
+		formats = append(formats, parser.ReportFormat(strings.TrimSpace(format)))
+	}
 	logLevel := cliContext.String("log-level")
 	level, err := logrus.ParseLevel(logLevel)
 	if err != nil {
@@ -71,10 +115,36 @@ func run(cliContext *cli.Context) error {
 	}
 	logger.SetLevel(level)
 
+	outputDir, err = filepath.Abs(outputDir)
+	if err != nil {
+		logger.Fatalf("Error extracting absolute path of output directory: %s", err)
+	}
+
+	follow := cliContext.Bool("follow")
+	if follow && len(filenames) > 1 {
+		return errors.WithStackTrace(fmt.Errorf("--follow can only be used with a single test log"))
+	}
+
+	if len(filenames) > 1 {
+		logger.Infof("reading from %d files", len(filenames))
+		shards := []parser.Shard{}
+		for _, filename := range filenames {
+			file, err := os.Open(filename)
+			if err != nil {
+				logger.Fatalf("Error opening file: %s", err)
+			}
+			defer file.Close()
+			name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+			shards = append(shards, parser.Shard{Name: name, Reader: file})
+		}
+		parser.SpawnShardParsers(logger, shards, outputDir, formats)
+		return nil
+	}
+
 	var file *os.File
-	if filename != "" {
+	if len(filenames) == 1 {
 		logger.Infof("reading from file")
-		file, err = os.Open(filename)
+		file, err = os.Open(filenames[0])
 		if err != nil {
 			logger.Fatalf("Error opening file: %s", err)
 		}
@@ -84,15 +154,70 @@ func run(cliContext *cli.Context) error {
 	}
 	defer file.Close()
 
-	outputDir, err = filepath.Abs(outputDir)
+	if follow {
+		parser.SpawnFollowingParsers(logger, file, outputDir, formats, cliContext.Duration("follow-interval"))
+	} else {
+		parser.SpawnParsersWithFormats(logger, file, outputDir, formats)
+	}
+	return nil
+}
+
+// expandTestLogs expands the globs in the given test log paths. Paths that are not globs are returned as is, so that a
+// missing file is reported when it is opened.
+func expandTestLogs(testLogs []string) ([]string, error) {
+	filenames := []string{}
+	for _, testLog := range testLogs {
+		matches, err := filepath.Glob(testLog)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		if len(matches) == 0 {
+			matches = []string{testLog}
+		}
+		filenames = append(filenames, matches...)
+	}
+	return filenames, nil
+}
+
+func runFlaky(cliContext *cli.Context) error {
+	level, err := logrus.ParseLevel(cliContext.GlobalString("log-level"))
 	if err != nil {
-		logger.Fatalf("Error extracting absolute path of output directory: %s", err)
+		return errors.WithStackTrace(err)
+	}
+	logger.SetLevel(level)
+
+	if cliContext.NArg() == 0 {
+		return errors.WithStackTrace(fmt.Errorf("Expected at least one output directory of a previous run"))
+	}
+	runs := []parser.FlakinessRun{}
+	for _, arg := range cliContext.Args() {
+		runs = append(runs, parseFlakinessRun(arg))
 	}
 
-	parser.SpawnParsers(logger, file, outputDir)
+	report, err := parser.AnalyzeFlakiness(logger, runs)
+	if err != nil {
+		return err
+	}
+	outputDir, err := filepath.Abs(cliContext.String("outputdir"))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if err := parser.StoreFlakinessReport(logger, outputDir, report); err != nil {
+		return err
+	}
+	parser.WriteFlakinessText(os.Stdout, report)
 	return nil
 }
 
+// parseFlakinessRun parses a run given as DIR@COMMIT, where the commit is optional.
+func parseFlakinessRun(arg string) parser.FlakinessRun {
+	index := strings.LastIndex(arg, "@")
+	if index < 0 {
+		return parser.FlakinessRun{Dir: arg}
+	}
+	return parser.FlakinessRun{Dir: arg[:index], Commit: arg[index+1:]}
+}
+
 func main() {
 	app := entrypoint.NewApp()
 	cli.AppHelpTemplate = CUSTOM_USAGE_TEXT
@@ -109,16 +234,29 @@ func main() {
 	}
 	defaultOutputDir := filepath.Join(currentDir, "out")
 
-	logInputFlag := cli.StringFlag{
+	logInputFlag := cli.StringSliceFlag{
 		Name:  "testlog, l",
-		Value: "",
-		Usage: "Path to file containing test log. If unset will use stdin.",
+		Usage: "Path to file containing test log (either go test -v or go test -json output). If unset will use stdin. Can be passed multiple times, or be a glob, to merge the logs of a sharded test suite.",
 	}
 	outputDirFlag := cli.StringFlag{
 		Name:  "outputdir, o",
 		Value: defaultOutputDir,
 		Usage: "Path to directory to output test output to. If unset will use the current directory.",
 	}
+	formatFlag := cli.StringFlag{
+		Name:  "format, f",
+		Value: string(parser.JUnitFormat),
+		Usage: fmt.Sprintf("Comma separated list of report formats to produce. Must be one of: %v", []parser.ReportFormat{parser.JUnitFormat, parser.HTMLFormat}),
+	}
+	followFlag := cli.BoolFlag{
+		Name:  "follow",
+		Usage: "Rewrite live_summary.log in the output directory with the running, passed and failed tests while the test log is read. Use when piping a running go test into the parser.",
+	}
+	followIntervalFlag := cli.DurationFlag{
+		Name:  "follow-interval",
+		Value: 10 * time.Second,
+		Usage: "How often to rewrite live_summary.log in follow mode.",
+	}
 	logLevelFlag := cli.StringFlag{
 		Name:  "log-level",
 		Value: logrus.InfoLevel.String(),
@@ -128,6 +266,24 @@ func main() {
 		logLevelFlag,
 		logInputFlag,
 		outputDirFlag,
+		formatFlag,
+		followFlag,
+		followIntervalFlag,
+	}
+	app.Commands = []cli.Command{
+		{
+			Name:      "flaky",
+			Usage:     "Report the pass rate of each test across the output directories of previous runs, along with the tests that flipped between pass and fail on the same commit.",
+			ArgsUsage: "RUN_DIR[@COMMIT]...",
+			Action:    runFlaky,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "outputdir, o",
+					Value: filepath.Join(currentDir, "flaky"),
+					Usage: "Path to directory to write flakiness.txt and flakiness.json to.",
+				},
+			},
+		},
 	}
 
 	entrypoint.RunApp(app)
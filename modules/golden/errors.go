@@ -0,0 +1,22 @@
+package golden
+
+import "fmt"
+
+// GoldenFileNotFound is returned when the golden file to compare against does not exist.
+type GoldenFileNotFound struct {
+	Path string
+}
+
+func (err GoldenFileNotFound) Error() string {
+	return fmt.Sprintf("Golden file %s does not exist. Run the test with %s=true to generate it.", err.Path, UPDATE_ENV_VAR)
+}
+
+// GoldenFileMismatch is returned when the actual output does not match the golden file.
+type GoldenFileMismatch struct {
+	Path string
+	Diff string
+}
+
+func (err GoldenFileMismatch) Error() string {
+	return fmt.Sprintf("Output does not match golden file %s. If the change is expected, run the test with %s=true to update it.\n\n%s", err.Path, UPDATE_ENV_VAR, err.Diff)
+}
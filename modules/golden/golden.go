@@ -0,0 +1,114 @@
+// Package golden allows to snapshot test outputs, such as the templates rendered by helm or the resource changes in a
+// Terraform plan, by comparing them against golden files committed along with the tests.
+package golden
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// UPDATE_ENV_VAR is the environment variable that, when set, makes the assertions of this package (re)generate the
+// golden files with the actual outputs instead of comparing against them. For example:
+//
+// TERRATEST_UPDATE_GOLDEN=true go test ./...
+const UPDATE_ENV_VAR = "TERRATEST_UPDATE_GOLDEN"
+
+// GoldenFileDir is the folder, relative to the package of the test, where the golden files are stored.
+const GoldenFileDir = "testdata"
+
+// IsUpdating returns true if the golden files are being (re)generated, because the tests run with the
+// TERRATEST_UPDATE_GOLDEN environment variable set.
+func IsUpdating() bool {
+	return os.Getenv(UPDATE_ENV_VAR) != ""
+}
+
+// FormatGoldenFilePath formats the path of the golden file with the given name: testdata/<name>.golden.
+func FormatGoldenFilePath(name string) string {
+	return filepath.Join(GoldenFileDir, name+".golden")
+}
+
+// AssertString checks that the given string matches the golden file with the given name, stored in the testdata folder
+// of the package of the test, and fails the test with a unified diff if it doesn't. When updating the golden files,
+// the golden file is (re)written with the given string instead.
+func AssertString(t testing.TestingT, name string, actual string) {
+	require.NoError(t, AssertStringE(t, name, actual))
+}
+
+// AssertStringE checks that the given string matches the golden file with the given name, stored in the testdata
+// folder of the package of the test, and returns a GoldenFileMismatch error with a unified diff if it doesn't. When
+// updating the golden files, the golden file is (re)written with the given string instead.
+func AssertStringE(t testing.TestingT, name string, actual string) error {
+	return AssertFileE(t, FormatGoldenFilePath(name), actual)
+}
+
+// AssertFile checks that the given string matches the golden file at the given path, and fails the test with a unified
+// diff if it doesn't. When updating the golden files, the golden file is (re)written with the given string instead.
+func AssertFile(t testing.TestingT, path string, actual string) {
+	require.NoError(t, AssertFileE(t, path, actual))
+}
+
+// AssertFileE checks that the given string matches the golden file at the given path, and returns a GoldenFileMismatch
+// error with a unified diff if it doesn't. When updating the golden files, the golden file is (re)written with the
+// given string instead.
+func AssertFileE(t testing.TestingT, path string, actual string) error {
+	if IsUpdating() {
+		logger.Logf(t, "Updating golden file %s", path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.WithStackTrace(err)
+		}
+		return errors.WithStackTrace(ioutil.WriteFile(path, []byte(actual), 0644))
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return errors.WithStackTrace(GoldenFileNotFound{Path: path})
+	}
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if string(expected) == actual {
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(expected)),
+		B:        splitLines(actual),
+		FromFile: path,
+		ToFile:   "actual",
+		Context:  3,
+	})
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return GoldenFileMismatch{Path: path, Diff: diff}
+}
+
+// splitLines splits the given text into lines for the diff, keeping the line endings. Unlike difflib.SplitLines, it
+// doesn't add an empty line at the end of the text.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// normalizeLines removes the trailing whitespace of every line and ensures the text ends with a single newline, so
+// that editors and formatters touching the golden files don't break the comparison.
+func normalizeLines(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
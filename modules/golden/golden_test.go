@@ -0,0 +1,71 @@
+package golden
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertFileReportsUnifiedDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.golden")
+
+	err := AssertFileE(t, path, "a\nb\nc\n")
+	require.Error(t, err)
+	assert.IsType(t, GoldenFileNotFound{}, errors.Unwrap(err))
+
+	t.Setenv(UPDATE_ENV_VAR, "true")
+	AssertFile(t, path, "a\nb\nc\n")
+
+	t.Setenv(UPDATE_ENV_VAR, "")
+	AssertFile(t, path, "a\nb\nc\n")
+
+	err = AssertFileE(t, path, "a\nB\nc\n")
+	require.Error(t, err)
+	mismatch, ok := err.(GoldenFileMismatch)
+	require.True(t, ok)
+	assert.Equal(t, "--- "+path+"\n+++ actual\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n", mismatch.Diff)
+}
+
+func TestAssertHelmTemplate(t *testing.T) {
+	t.Parallel()
+
+	rendered := "---\n# Source: nginx/templates/service.yaml   \napiVersion: v1\nkind: Service\nmetadata:\n  name: nginx\n\n"
+	AssertHelmTemplate(t, "helm_template", rendered)
+}
+
+func TestAssertPlanResourceChanges(t *testing.T) {
+	t.Parallel()
+
+	plan := &terraform.PlanStruct{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"format_version": "0.2",
+		"resource_changes": [
+			{
+				"address": "aws_instance.web",
+				"change": {
+					"actions": ["create"],
+					"before": null,
+					"after": {"ami": "ami-123", "tags": {"Name": "web"}, "tags_all": {"Name": "web"}, "user_data": "secret", "ebs_block_device": [{"volume_size": 8}]},
+					"after_unknown": {"arn": true, "id": true, "ebs_block_device": [{"volume_id": true}]},
+					"after_sensitive": {"user_data": true}
+				}
+			},
+			{
+				"address": "aws_eip.web",
+				"change": {
+					"actions": ["delete", "create"],
+					"before": {"id": "eipalloc-1", "instance": "i-123"},
+					"after": {},
+					"after_unknown": {"id": true, "instance": true}
+				}
+			}
+		]
+	}`), &plan.RawPlan))
+
+	AssertPlanResourceChanges(t, "plan_resource_changes", plan, "tags_all")
+}
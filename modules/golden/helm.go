@@ -0,0 +1,21 @@
+package golden
+
+import (
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// AssertHelmTemplate checks that the templates rendered by helm.RenderTemplate match the golden file with the given
+// name, and fails the test with a unified diff if they don't. Trailing whitespace is ignored. Pass the templateFiles
+// to helm.RenderTemplate to snapshot only the templates under test, so that unrelated changes to the chart don't break
+// the test.
+func AssertHelmTemplate(t testing.TestingT, name string, rendered string) {
+	require.NoError(t, AssertHelmTemplateE(t, name, rendered))
+}
+
+// AssertHelmTemplateE checks that the templates rendered by helm.RenderTemplate match the golden file with the given
+// name, and returns a GoldenFileMismatch error with a unified diff if they don't. Trailing whitespace is ignored.
+func AssertHelmTemplateE(t testing.TestingT, name string, rendered string) error {
+	return AssertStringE(t, name, normalizeLines(rendered))
+}
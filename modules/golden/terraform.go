@@ -0,0 +1,141 @@
+package golden
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// unknownValue replaces the values that are only known after apply, such as the IDs of the resources to create.
+const unknownValue = "(known after apply)"
+
+// sensitiveValue replaces the sensitive values, so that they don't end up in the golden files.
+const sensitiveValue = "(sensitive)"
+
+// plannedResourceChange is the snapshot of the planned change of a resource.
+type plannedResourceChange struct {
+	Address string      `json:"address"`
+	Actions []string    `json:"actions"`
+	Before  interface{} `json:"before"`
+	After   interface{} `json:"after"`
+}
+
+// AssertPlanResourceChanges checks that the resource changes in the given plan match the golden file with the given
+// name, and fails the test with a unified diff if they don't. See AssertPlanResourceChangesE for how the changes are
+// snapshotted.
+func AssertPlanResourceChanges(t testing.TestingT, name string, plan *terraform.PlanStruct, ignoredAttributes ...string) {
+	require.NoError(t, AssertPlanResourceChangesE(t, name, plan, ignoredAttributes...))
+}
+
+// AssertPlanResourceChangesE checks that the resource changes in the given plan (e.g., from
+// terraform.InitAndPlanAndShowWithStruct) match the golden file with the given name, and returns a GoldenFileMismatch
+// error with a unified diff if they don't. The changes are snapshotted as JSON sorted by resource address, with the
+// fields that vary from run to run stripped: the values only known after apply are replaced with "(known after
+// apply)", the sensitive values with "(sensitive)", and the given attributes (e.g., "tags_all" or values derived from
+// random names) are removed at any depth.
+func AssertPlanResourceChangesE(t testing.TestingT, name string, plan *terraform.PlanStruct, ignoredAttributes ...string) error {
+	snapshot, err := formatPlanResourceChanges(plan, ignoredAttributes)
+	if err != nil {
+		return err
+	}
+	return AssertStringE(t, name, snapshot)
+}
+
+// formatPlanResourceChanges formats the snapshot of the resource changes in the given plan.
+func formatPlanResourceChanges(plan *terraform.PlanStruct, ignoredAttributes []string) (string, error) {
+	ignored := map[string]bool{}
+	for _, attribute := range ignoredAttributes {
+		ignored[attribute] = true
+	}
+
+	changes := []plannedResourceChange{}
+	for _, resourceChange := range plan.RawPlan.ResourceChanges {
+		if resourceChange.Change == nil {
+			continue
+		}
+		change := resourceChange.Change
+
+		actions := []string{}
+		for _, action := range change.Actions {
+			actions = append(actions, string(action))
+		}
+
+		before := stripPlanValue(change.Before, nil, change.BeforeSensitive, ignored)
+		after := stripPlanValue(change.After, change.AfterUnknown, change.AfterSensitive, ignored)
+		changes = append(changes, plannedResourceChange{Address: resourceChange.Address, Actions: actions, Before: before, After: after})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Address < changes[j].Address })
+
+	out, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	return string(out) + "\n", nil
+}
+
+// stripPlanValue returns a copy of the given value from a plan with the unknown values (marked as true in the unknown
+// tree), the sensitive values (marked as true in the sensitive tree) and the ignored attributes replaced or removed.
+// The unknown and sensitive trees mirror the structure of the value, as in the after_unknown and after_sensitive
+// fields of the JSON plan.
+func stripPlanValue(value interface{}, unknown interface{}, sensitive interface{}, ignored map[string]bool) interface{} {
+	if isMarked(sensitive) {
+		return sensitiveValue
+	}
+	if isMarked(unknown) {
+		return unknownValue
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		out := map[string]interface{}{}
+		for key, item := range typed {
+			if ignored[key] {
+				continue
+			}
+			out[key] = stripPlanValue(item, childMarks(unknown, key), childMarks(sensitive, key), ignored)
+		}
+		// The attributes that are entirely unknown are only in the unknown tree
+		if unknownMap, ok := unknown.(map[string]interface{}); ok {
+			for key, mark := range unknownMap {
+				if _, exists := out[key]; !exists && !ignored[key] && isMarked(mark) {
+					out[key] = unknownValue
+				}
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(typed))
+		for i, item := range typed {
+			out[i] = stripPlanValue(item, childMarks(unknown, i), childMarks(sensitive, i), ignored)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// isMarked returns true if the given node of an unknown or sensitive tree marks the whole value.
+func isMarked(marks interface{}) bool {
+	marked, ok := marks.(bool)
+	return ok && marked
+}
+
+// childMarks returns the node of an unknown or sensitive tree for the given map key or list index.
+func childMarks(marks interface{}, keyOrIndex interface{}) interface{} {
+	switch typed := marks.(type) {
+	case map[string]interface{}:
+		if key, ok := keyOrIndex.(string); ok {
+			return typed[key]
+		}
+	case []interface{}:
+		if index, ok := keyOrIndex.(int); ok && index < len(typed) {
+			return typed[index]
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package helm
 
 import (
 	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
@@ -63,6 +64,8 @@ func RunHelmCommandAndGetStdOutE(t testing.TestingT, options *Options, cmd strin
 }
 
 func prepareHelmCommand(t testing.TestingT, options *Options, cmd string, additionalArgs ...string) shell.Command {
+	markSensitiveSetValues(options)
+
 	args := []string{cmd}
 	args = getCommonArgs(options, args...)
 	args = append(args, getNamespaceArgs(options)...)
@@ -82,3 +85,16 @@ func prepareHelmCommand(t testing.TestingT, options *Options, cmd string, additi
 	}
 	return helmCmd
 }
+
+// markSensitiveSetValues marks the values of the SetValues and SetStrValues listed in SensitiveSetValues as sensitive in
+// the logger package, so they are redacted from the log lines and error messages.
+func markSensitiveSetValues(options *Options) {
+	for _, key := range options.SensitiveSetValues {
+		if value, hasValue := options.SetValues[key]; hasValue {
+			logger.MarkSensitive(value)
+		}
+		if value, hasValue := options.SetStrValues[key]; hasValue {
+			logger.MarkSensitive(value)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package helm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+)
+
+func TestPrepareHelmCommandMarksSensitiveSetValues(t *testing.T) {
+	t.Parallel()
+
+	password := fmt.Sprintf("%s-password", t.Name())
+	token := fmt.Sprintf("%s-token", t.Name())
+	public := fmt.Sprintf("%s-public", t.Name())
+	options := &Options{
+		SetValues:          map[string]string{"db.password": password, "image.tag": public},
+		SetStrValues:       map[string]string{"api.token": token},
+		SensitiveSetValues: []string{"db.password", "api.token"},
+	}
+	prepareHelmCommand(t, options, "install")
+
+	assert.Equal(t, fmt.Sprintf("*** *** %s", public), logger.Redact(fmt.Sprintf("%s %s %s", password, token, public)))
+}
@@ -2,6 +2,10 @@ package helm
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+
+	goversion "github.com/hashicorp/go-version"
 )
 
 // ValuesFileNotFoundError is returned when a provided values file input is not found on the host path.
@@ -40,3 +44,161 @@ type ChartNotFoundError struct {
 func (err ChartNotFoundError) Error() string {
 	return fmt.Sprintf("Could not chart path %s", err.Path)
 }
+
+// DuplicateRenderedObjectError is returned when the rendered templates contain more than one object with the same kind,
+// namespace and name.
+type DuplicateRenderedObjectError struct {
+	Key RenderedObjectKey
+}
+
+func (err DuplicateRenderedObjectError) Error() string {
+	return fmt.Sprintf("Rendered more than one %s named %s in namespace '%s'", err.Key.Kind, err.Key.Name, err.Key.Namespace)
+}
+
+// RenderedObjectNotFoundError is returned when there is no rendered object with the given kind and name.
+type RenderedObjectNotFoundError struct {
+	Kind string
+	Name string
+}
+
+func (err RenderedObjectNotFoundError) Error() string {
+	return fmt.Sprintf("Could not find a rendered %s named %s", err.Kind, err.Name)
+}
+
+// AmbiguousRenderedObjectError is returned when there are rendered objects with the given kind and name in more than
+// one namespace.
+type AmbiguousRenderedObjectError struct {
+	Kind string
+	Name string
+}
+
+func (err AmbiguousRenderedObjectError) Error() string {
+	return fmt.Sprintf("Rendered more than one %s named %s, in different namespaces: use Lookup with the namespace instead", err.Kind, err.Name)
+}
+
+// TestHooksFailedError is returned when some of the test hooks run by `helm test` failed.
+type TestHooksFailedError struct {
+	ReleaseName string
+	Failed      []TestHookResult
+}
+
+func (err TestHooksFailedError) Error() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%d test hook(s) of release %s failed:", len(err.Failed), err.ReleaseName)
+	for _, result := range err.Failed {
+		fmt.Fprintf(&out, "\n\n=== %s %s (phase %s)", result.Kind, result.Name, result.Phase)
+		containers := []string{}
+		for container := range result.Logs {
+			containers = append(containers, container)
+		}
+		sort.Strings(containers)
+		for _, container := range containers {
+			fmt.Fprintf(&out, "\n--- logs of container %s:\n%s", container, strings.TrimRight(result.Logs[container], "\n"))
+		}
+	}
+	return out.String()
+}
+
+// LintFailedError is returned when `helm lint --strict` reports errors or warnings for a chart.
+type LintFailedError struct {
+	ChartDir string
+	Messages []LintMessage
+}
+
+func (err LintFailedError) Error() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "Chart %s has %d lint error(s) or warning(s):", err.ChartDir, len(err.Messages))
+	for _, message := range err.Messages {
+		fmt.Fprintf(&out, "\n[%s] %s: %s", message.Severity, message.Path, message.Message)
+	}
+	return out.String()
+}
+
+// ValuesSchemaNotFoundError is returned when validating values files for a chart without a values.schema.json.
+type ValuesSchemaNotFoundError struct {
+	Path string
+}
+
+func (err ValuesSchemaNotFoundError) Error() string {
+	return fmt.Sprintf("Could not find values schema %s", err.Path)
+}
+
+// ValuesSchemaValidationError is returned when values files don't conform to the values.schema.json of a chart.
+type ValuesSchemaValidationError struct {
+	SchemaPath string
+	Failures   map[string][]string // The problems of each values file that doesn't conform, by path
+}
+
+func (err ValuesSchemaValidationError) Error() string {
+	paths := []string{}
+	for path := range err.Failures {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%d values file(s) do not conform to %s:", len(paths), err.SchemaPath)
+	for _, path := range paths {
+		fmt.Fprintf(&out, "\n%s:", path)
+		for _, problem := range err.Failures[path] {
+			fmt.Fprintf(&out, "\n  - %s", problem)
+		}
+	}
+	return out.String()
+}
+
+// UnexpectedOutputError is returned when the output of a helm command can't be parsed.
+type UnexpectedOutputError struct {
+	Command string
+	Output  string
+}
+
+func (err UnexpectedOutputError) Error() string {
+	return fmt.Sprintf("Could not parse the output of helm %s:\n%s", err.Command, err.Output)
+}
+
+// RemovedAPIsError is returned when the objects rendered from a chart use APIs removed in the Kubernetes versions they
+// were rendered for.
+type RemovedAPIsError struct {
+	ChartDir string
+	Usages   map[string][]RemovedAPIUsage // The offending objects, by Kubernetes version
+}
+
+func (err RemovedAPIsError) Error() string {
+	kubeVersions := []string{}
+	for kubeVersion := range err.Usages {
+		kubeVersions = append(kubeVersions, kubeVersion)
+	}
+	sort.Slice(kubeVersions, func(i, j int) bool { return kubeVersionLess(kubeVersions[i], kubeVersions[j]) })
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Chart %s renders objects with removed APIs:", err.ChartDir)
+	for _, kubeVersion := range kubeVersions {
+		fmt.Fprintf(&out, "\nKubernetes %s:", kubeVersion)
+		for _, usage := range err.Usages[kubeVersion] {
+			fmt.Fprintf(&out, "\n  - %s %s", usage.Object.Kind, usage.Object.Name)
+			if usage.Object.Namespace != "" {
+				fmt.Fprintf(&out, " in namespace %s", usage.Object.Namespace)
+			}
+			fmt.Fprintf(&out, " uses %s, removed in %s", usage.API.APIVersion, usage.API.RemovedIn)
+			if usage.API.Replacement != "" {
+				fmt.Fprintf(&out, " (use %s instead)", usage.API.Replacement)
+			}
+		}
+	}
+	return out.String()
+}
+
+// kubeVersionLess returns true if the given Kubernetes version is older than the other one, so that e.g. 1.9 sorts
+// before 1.25. The versions that can't be parsed are compared as strings.
+func kubeVersionLess(kubeVersion string, otherKubeVersion string) bool {
+	version, err := goversion.NewVersion(kubeVersion)
+	if err != nil {
+		return kubeVersion < otherKubeVersion
+	}
+	otherVersion, err := goversion.NewVersion(otherKubeVersion)
+	if err != nil {
+		return kubeVersion < otherKubeVersion
+	}
+	return version.LessThan(otherVersion)
+}
@@ -10,8 +10,10 @@ import (
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
 
-// Install will install the selected helm chart with the provided options under the given release name. This will fail
-// the test if there is an error.
+// Install will install the selected helm chart with the provided options under the given release name. The chart can
+// be a local path, a chart in a repository (e.g., stable/nginx), or a chart in an OCI registry (e.g.,
+// oci://localhost:5000/charts/nginx), with the version to install in options.Version. This will fail the test if there
+// is an error.
 func Install(t testing.TestingT, options *Options, chart string, releaseName string) {
 	require.NoError(t, InstallE(t, options, chart, releaseName))
 }
@@ -0,0 +1,88 @@
+package helm
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// The severities of the lint messages.
+const (
+	LintSeverityInfo    = "INFO"
+	LintSeverityWarning = "WARNING"
+	LintSeverityError   = "ERROR"
+)
+
+// lintMessageRegexp matches the messages in the output of `helm lint`, e.g.:
+// [WARNING] templates/deployment.yaml: object name does not conform to Kubernetes naming requirements
+var lintMessageRegexp = regexp.MustCompile(`^\[(INFO|WARNING|ERROR)\] (?:([^:\s]*): )?(.*)$`)
+
+// LintMessage is a message reported by `helm lint`.
+type LintMessage struct {
+	Severity string // One of LintSeverityInfo, LintSeverityWarning or LintSeverityError
+	Path     string // The path of the file in the chart the message is about, e.g., templates/deployment.yaml
+	Message  string
+}
+
+// Lint runs `helm lint --strict` on the chart in the given folder, with the values of the given options, and returns
+// the messages it reports. This will fail the test if the chart has any lint error or warning.
+func Lint(t testing.TestingT, options *Options, chartDir string) []LintMessage {
+	messages, err := LintE(t, options, chartDir)
+	require.NoError(t, err)
+	return messages
+}
+
+// LintE runs `helm lint --strict` on the chart in the given folder, with the values of the given options, and returns
+// the messages it reports. Returns a LintFailedError along with the messages if the chart has any lint error or
+// warning.
+func LintE(t testing.TestingT, options *Options, chartDir string) ([]LintMessage, error) {
+	if !files.FileExists(chartDir) {
+		return nil, errors.WithStackTrace(ChartNotFoundError{chartDir})
+	}
+
+	args := []string{"--strict"}
+	args, err := getValuesArgsE(t, options, args...)
+	if err != nil {
+		return nil, err
+	}
+	if options.ExtraArgs != nil {
+		if lintArgs, ok := options.ExtraArgs["lint"]; ok {
+			args = append(args, lintArgs...)
+		}
+	}
+	args = append(args, chartDir)
+
+	// helm exits with an error when there are lint errors or, with --strict, warnings, but still reports the messages
+	out, lintErr := RunHelmCommandAndGetOutputE(t, options, "lint", args...)
+	messages := parseLintOutput(out)
+
+	failed := []LintMessage{}
+	for _, message := range messages {
+		if message.Severity != LintSeverityInfo {
+			failed = append(failed, message)
+		}
+	}
+	if len(failed) > 0 {
+		return messages, errors.WithStackTrace(LintFailedError{ChartDir: chartDir, Messages: failed})
+	}
+	return messages, lintErr
+}
+
+// parseLintOutput parses the messages from the output of `helm lint`.
+func parseLintOutput(out string) []LintMessage {
+	messages := []LintMessage{}
+	for _, line := range strings.Split(out, "\n") {
+		match := lintMessageRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		messages = append(messages, LintMessage{Severity: match[1], Path: filepath.ToSlash(match[2]), Message: match[3]})
+	}
+	return messages
+}
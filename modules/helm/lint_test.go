@@ -0,0 +1,79 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	options := fakeHelm(t, map[string]string{
+		"lint --strict": `==> Linting ./nginx
+[INFO] Chart.yaml: icon is recommended
+[WARNING] templates/deployment.yaml: object name does not conform to Kubernetes naming requirements: "Nginx"
+[ERROR] : unable to load chart
+
+Error: 1 chart(s) linted, 1 chart(s) failed`,
+	})
+
+	messages, err := LintE(t, options, t.TempDir())
+	require.Error(t, err)
+	assert.Equal(t, []LintMessage{
+		{Severity: LintSeverityInfo, Path: "Chart.yaml", Message: "icon is recommended"},
+		{Severity: LintSeverityWarning, Path: "templates/deployment.yaml", Message: `object name does not conform to Kubernetes naming requirements: "Nginx"`},
+		{Severity: LintSeverityError, Path: "", Message: "unable to load chart"},
+	}, messages)
+
+	lintErr, ok := errors.Unwrap(err).(LintFailedError)
+	require.True(t, ok)
+	assert.Len(t, lintErr.Messages, 2)
+}
+
+func TestValidateValuesFiles(t *testing.T) {
+	t.Parallel()
+
+	chartDir := t.TempDir()
+	writeFile(t, filepath.Join(chartDir, "values.schema.json"), `{
+		"type": "object",
+		"required": ["image"],
+		"properties": {
+			"replicaCount": {"type": "integer", "minimum": 1},
+			"image": {
+				"type": "object",
+				"required": ["repository"],
+				"properties": {"repository": {"type": "string"}, "tag": {"type": "string"}}
+			}
+		}
+	}`)
+	writeFile(t, filepath.Join(chartDir, "values.yaml"), "replicaCount: 1\nimage:\n  repository: nginx\n")
+
+	valid := filepath.Join(chartDir, "examples", "valid.yaml")
+	writeFile(t, valid, "image:\n  tag: \"1.21\"\n")
+	invalid := filepath.Join(chartDir, "examples", "invalid.yaml")
+	writeFile(t, invalid, "replicaCount: 0\nimage:\n  repository: null\n  tag: 1.21\n")
+
+	ValidateValuesFiles(t, chartDir, valid)
+
+	err := ValidateValuesFilesE(t, chartDir, valid, invalid)
+	require.Error(t, err)
+	validationErr, ok := errors.Unwrap(err).(ValuesSchemaValidationError)
+	require.True(t, ok)
+	assert.NotContains(t, validationErr.Failures, valid)
+	assert.ElementsMatch(t, []string{
+		"replicaCount: Must be greater than or equal to 1",
+		"image: repository is required",
+		"image.tag: Invalid type. Expected: string, given: number",
+	}, validationErr.Failures[invalid])
+}
+
+func writeFile(t *testing.T, path string, contents string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+}
@@ -0,0 +1,70 @@
+//go:build kubeall || helm
+// +build kubeall helm
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests, and further differentiate helm
+// tests. This is done because minikube is heavy and can interfere with docker related tests in terratest. Similarly,
+// helm can overload the minikube system and thus interfere with the other kubernetes tests. To avoid overloading the
+// system, we run the kubernetes tests and helm tests separately from the others.
+
+package helm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/docker"
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/random"
+)
+
+// Test that we can package a chart, push it to an OCI registry, and install it from there.
+func TestPackagePushAndInstallFromOCIRegistry(t *testing.T) {
+	t.Parallel()
+
+	// Start a local OCI registry
+	uniqueID := strings.ToLower(random.UniqueId())
+	registryName := fmt.Sprintf("terratest-registry-%s", uniqueID)
+	registryID := docker.RunAndGetID(t, "registry:2", &docker.RunOptions{
+		Detach:       true,
+		Remove:       true,
+		Name:         registryName,
+		OtherOptions: []string{"--publish", "5000"},
+	})
+	defer docker.Stop(t, []string{registryID}, &docker.StopOptions{})
+	registry := docker.Inspect(t, registryID)
+	require.NotEmpty(t, registry.Ports)
+	registryHost := fmt.Sprintf("localhost:%d", registry.Ports[0].HostPort)
+	http_helper.HttpGetWithRetry(t, fmt.Sprintf("http://%s/v2/", registryHost), nil, 200, "{}", 10, time.Second)
+
+	namespaceName := fmt.Sprintf("terratest-helm-oci-%s", uniqueID)
+	kubectlOptions := k8s.NewKubectlOptions("", "", namespaceName)
+	k8s.CreateNamespace(t, kubectlOptions, namespaceName)
+	defer k8s.DeleteNamespace(t, kubectlOptions, namespaceName)
+
+	options := &Options{
+		KubectlOptions: kubectlOptions,
+		Version:        "0.0.1-" + uniqueID,
+		SetValues:      map[string]string{"containerImageRepo": "nginx", "containerImageTag": "1.15.8"},
+		ExtraArgs:      map[string][]string{"push": {"--plain-http"}, "install": {"--plain-http"}},
+	}
+
+	// Package the chart with a unique version and push it to the registry
+	chartArchive := Package(t, options, "../../examples/helm-basic-example", t.TempDir())
+	pushed := Push(t, options, chartArchive, fmt.Sprintf("oci://%s/charts", registryHost))
+	assert.Equal(t, fmt.Sprintf("%s/charts/helm-basic-example:%s", registryHost, options.Version), pushed.Ref)
+
+	// Install the chart from the registry, with the version in the options
+	releaseName := fmt.Sprintf("oci-%s", uniqueID)
+	defer Delete(t, options, releaseName, true)
+	Install(t, options, fmt.Sprintf("oci://%s/charts/helm-basic-example", registryHost), releaseName)
+
+	release := GetReleaseStatus(t, options, releaseName)
+	require.Equal(t, "deployed", release.Info.Status)
+	assert.Equal(t, options.Version, release.Chart.Metadata.Version)
+}
@@ -17,4 +17,10 @@ type Options struct {
 	Logger         *logger.Logger      // Set a non-default logger that should be used. See the logger package for more info. Use logger.Discard to not print the output while executing the command.
 	ExtraArgs      map[string][]string // Extra arguments to pass to the helm install/upgrade/rollback/delete command. The key signals the command (e.g., install) while the values are the extra arguments to pass through.
 	Executable	   string              // the executable to use, defaults to `helm``
+	KubeVersion    string              // Kubernetes version used for Capabilities.KubeVersion when rendering templates (e.g., 1.22.0). Empty string means the helm default.
+	APIVersions    []string            // Kubernetes API versions added to Capabilities.APIVersions when rendering templates (e.g., networking.k8s.io/v1/Ingress).
+
+	// The keys of the SetValues and SetStrValues whose values are sensitive. Their values are marked as sensitive in the
+	// logger package before each helm command runs, so they are redacted from the log lines and error messages.
+	SensitiveSetValues []string
 }
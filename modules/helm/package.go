@@ -0,0 +1,172 @@
+package helm
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// packagedChartRegexp matches the path of the chart archive in the output of `helm package`.
+var packagedChartRegexp = regexp.MustCompile(`Successfully packaged chart and saved it to: (.+)`)
+
+// pushedChartRegexps match the reference and the digest of the chart in the output of `helm push`.
+var (
+	pushedChartRefRegexp    = regexp.MustCompile(`(?m)^Pushed: (\S+)`)
+	pushedChartDigestRegexp = regexp.MustCompile(`(?m)^Digest: (\S+)`)
+)
+
+// PushedChart is a chart pushed to an OCI registry with `helm push`.
+type PushedChart struct {
+	Ref    string // The reference of the chart, without the oci:// scheme, e.g., localhost:5000/charts/nginx:0.1.0
+	Digest string // The digest of the chart, e.g., sha256:0123...
+}
+
+// Package runs `helm package` to package the chart in the given folder into a chart archive in the destination
+// folder, and returns the path of the chart archive. If options.Version is set, it overrides the version of the chart.
+// This will fail the test if there is an error.
+func Package(t testing.TestingT, options *Options, chartDir string, destinationDir string) string {
+	path, err := PackageE(t, options, chartDir, destinationDir)
+	require.NoError(t, err)
+	return path
+}
+
+// PackageE runs `helm package` to package the chart in the given folder into a chart archive in the destination
+// folder, and returns the path of the chart archive. If options.Version is set, it overrides the version of the chart.
+func PackageE(t testing.TestingT, options *Options, chartDir string, destinationDir string) (string, error) {
+	if !files.FileExists(chartDir) {
+		return "", errors.WithStackTrace(ChartNotFoundError{chartDir})
+	}
+
+	args := []string{}
+	if options.ExtraArgs != nil {
+		if packageArgs, ok := options.ExtraArgs["package"]; ok {
+			args = append(args, packageArgs...)
+		}
+	}
+	if options.Version != "" {
+		args = append(args, "--version", options.Version)
+	}
+	if destinationDir != "" {
+		args = append(args, "--destination", destinationDir)
+	}
+	args = append(args, chartDir)
+
+	out, err := RunHelmCommandAndGetOutputE(t, options, "package", args...)
+	if err != nil {
+		return "", err
+	}
+	match := packagedChartRegexp.FindStringSubmatch(out)
+	if match == nil {
+		return "", errors.WithStackTrace(UnexpectedOutputError{Command: "package", Output: out})
+	}
+	return strings.TrimSpace(match[1]), nil
+}
+
+// UpdateDependencies runs `helm dependency update` to download the dependencies declared in the Chart.yaml of the
+// chart in the given folder into its charts folder. This will fail the test if there is an error.
+func UpdateDependencies(t testing.TestingT, options *Options, chartDir string) {
+	require.NoError(t, UpdateDependenciesE(t, options, chartDir))
+}
+
+// UpdateDependenciesE runs `helm dependency update` to download the dependencies declared in the Chart.yaml of the
+// chart in the given folder into its charts folder.
+func UpdateDependenciesE(t testing.TestingT, options *Options, chartDir string) error {
+	if !files.FileExists(chartDir) {
+		return errors.WithStackTrace(ChartNotFoundError{chartDir})
+	}
+	_, err := RunHelmCommandAndGetOutputE(t, options, "dependency", "update", chartDir)
+	return err
+}
+
+// RegistryLogin runs `helm registry login` to log in to the given OCI registry host (e.g., localhost:5000). The
+// password is passed on stdin and redacted from the logs. Pass `--insecure` in options.ExtraArgs["registry login"] for
+// registries without a trusted certificate. This will fail the test if there is an error.
+func RegistryLogin(t testing.TestingT, options *Options, host string, username string, password string) {
+	require.NoError(t, RegistryLoginE(t, options, host, username, password))
+}
+
+// RegistryLoginE runs `helm registry login` to log in to the given OCI registry host (e.g., localhost:5000). The
+// password is passed on stdin and redacted from the logs. Pass `--insecure` in options.ExtraArgs["registry login"] for
+// registries without a trusted certificate.
+func RegistryLoginE(t testing.TestingT, options *Options, host string, username string, password string) error {
+	args := []string{"login", host, "--username", username, "--password-stdin"}
+	if options.ExtraArgs != nil {
+		if loginArgs, ok := options.ExtraArgs["registry login"]; ok {
+			args = append(args, loginArgs...)
+		}
+	}
+
+	// The password is passed on stdin to keep it off the command line, and marked as sensitive in case helm echoes it
+	logger.MarkSensitive(password)
+	helmCmd := prepareHelmCommand(t, options, "registry", args...)
+	helmCmd.Stdin = strings.NewReader(password)
+	_, err := shell.RunCommandAndGetOutputE(t, helmCmd)
+	return err
+}
+
+// RegistryLogout runs `helm registry logout` to log out of the given OCI registry host. This will fail the test if
+// there is an error.
+func RegistryLogout(t testing.TestingT, options *Options, host string) {
+	require.NoError(t, RegistryLogoutE(t, options, host))
+}
+
+// RegistryLogoutE runs `helm registry logout` to log out of the given OCI registry host.
+func RegistryLogoutE(t testing.TestingT, options *Options, host string) error {
+	_, err := RunHelmCommandAndGetOutputE(t, options, "registry", "logout", host)
+	return err
+}
+
+// Push runs `helm push` to push the given chart archive (e.g., from Package) to the given OCI registry repository
+// (e.g., oci://localhost:5000/charts), and returns the reference and digest of the pushed chart. The chart can then be
+// installed with Install, using the repository and the chart name as the chart (e.g.,
+// oci://localhost:5000/charts/nginx) and the chart version as options.Version. Pass `--plain-http` or
+// `--insecure-skip-tls-verify` in options.ExtraArgs["push"] for local registries. This will fail the test if there is
+// an error.
+func Push(t testing.TestingT, options *Options, chartArchive string, remote string) PushedChart {
+	pushed, err := PushE(t, options, chartArchive, remote)
+	require.NoError(t, err)
+	return pushed
+}
+
+// PushE runs `helm push` to push the given chart archive (e.g., from Package) to the given OCI registry repository
+// (e.g., oci://localhost:5000/charts), and returns the reference and digest of the pushed chart. Pass `--plain-http`
+// or `--insecure-skip-tls-verify` in options.ExtraArgs["push"] for local registries.
+func PushE(t testing.TestingT, options *Options, chartArchive string, remote string) (PushedChart, error) {
+	if !files.FileExists(chartArchive) {
+		return PushedChart{}, errors.WithStackTrace(ChartNotFoundError{chartArchive})
+	}
+	absChartArchive, err := filepath.Abs(chartArchive)
+	if err != nil {
+		return PushedChart{}, errors.WithStackTrace(err)
+	}
+
+	args := []string{}
+	if options.ExtraArgs != nil {
+		if pushArgs, ok := options.ExtraArgs["push"]; ok {
+			args = append(args, pushArgs...)
+		}
+	}
+	args = append(args, absChartArchive, remote)
+
+	out, err := RunHelmCommandAndGetOutputE(t, options, "push", args...)
+	if err != nil {
+		return PushedChart{}, err
+	}
+	refMatch := pushedChartRefRegexp.FindStringSubmatch(out)
+	if refMatch == nil {
+		return PushedChart{}, errors.WithStackTrace(UnexpectedOutputError{Command: "push", Output: out})
+	}
+	pushed := PushedChart{Ref: refMatch[1]}
+	if digestMatch := pushedChartDigestRegexp.FindStringSubmatch(out); digestMatch != nil {
+		pushed.Digest = digestMatch[1]
+	}
+	return pushed, nil
+}
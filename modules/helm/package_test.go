@@ -0,0 +1,57 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+)
+
+func TestPackageAndPush(t *testing.T) {
+	t.Parallel()
+
+	chartArchive := filepath.Join(t.TempDir(), "nginx-0.1.0.tgz")
+	writeFile(t, chartArchive, "")
+	options := fakeHelm(t, map[string]string{
+		"package --version": "Successfully packaged chart and saved it to: " + chartArchive,
+		"push --plain-http": "Pushed: localhost:5000/charts/nginx:0.1.0\nDigest: sha256:0123456789abcdef",
+	})
+	options.Version = "0.1.0"
+	options.ExtraArgs = map[string][]string{"push": {"--plain-http"}}
+
+	assert.Equal(t, chartArchive, Package(t, options, t.TempDir(), t.TempDir()))
+	assert.Equal(t, PushedChart{Ref: "localhost:5000/charts/nginx:0.1.0", Digest: "sha256:0123456789abcdef"}, Push(t, options, chartArchive, "oci://localhost:5000/charts"))
+}
+
+func TestPackageUnexpectedOutput(t *testing.T) {
+	t.Parallel()
+
+	options := fakeHelm(t, map[string]string{"package --destination": "something else"})
+
+	_, err := PackageE(t, options, t.TempDir(), t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Could not parse the output of helm package")
+}
+
+func TestRegistryLoginPassesPasswordOnStdin(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	recordPath := filepath.Join(dir, "record")
+	helmPath := filepath.Join(dir, "helm")
+	writeFile(t, helmPath, "#!/bin/sh\necho \"args: $*\" > "+recordPath+"\necho \"stdin: $(cat)\" >> "+recordPath+"\n")
+	require.NoError(t, os.Chmod(helmPath, 0755))
+	options := &Options{Executable: helmPath, Logger: logger.Discard}
+
+	RegistryLogin(t, options, "localhost:5000", "admin", "registry-password")
+
+	record, err := ioutil.ReadFile(recordPath)
+	require.NoError(t, err)
+	assert.Equal(t, "args: registry login localhost:5000 --username admin --password-stdin\nstdin: registry-password\n", string(record))
+	assert.Equal(t, "password ***", logger.Redact("password registry-password"))
+}
@@ -0,0 +1,253 @@
+package helm
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// Release is a release installed in the cluster, as returned by `helm status -o json`.
+type Release struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Revision  int                    `json:"version"`
+	Info      ReleaseInfo            `json:"info"`
+	Chart     ReleaseChart           `json:"chart"`
+	Config    map[string]interface{} `json:"config"`   // The values supplied by the user, e.g., with SetValues
+	Manifest  string                 `json:"manifest"` // Only returned by GetReleaseE, as `helm status` omits it
+	Hooks     []ReleaseHook          `json:"hooks"`
+}
+
+// ReleaseInfo describes the state of a release.
+type ReleaseInfo struct {
+	FirstDeployed time.Time `json:"first_deployed"`
+	LastDeployed  time.Time `json:"last_deployed"`
+	Description   string    `json:"description"`
+	Status        string    `json:"status"` // e.g., deployed, failed, superseded, pending-upgrade
+	Notes         string    `json:"notes"`
+}
+
+// ReleaseHook is a hook of a release, such as a test hook run by `helm test`.
+type ReleaseHook struct {
+	Name    string        `json:"name"`
+	Kind    string        `json:"kind"`
+	Path    string        `json:"path"` // The path of the template of the hook in the chart
+	Events  []string      `json:"events"`
+	LastRun HookExecution `json:"last_run"`
+}
+
+// HookExecution is the last run of a hook. The times are zero if the hook never ran or is still running.
+type HookExecution struct {
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	Phase       string    `json:"phase"` // e.g., Unknown, Running, Succeeded, Failed
+}
+
+// UnmarshalJSON decodes the last run of a hook, for which helm outputs the zero times as empty strings.
+func (execution *HookExecution) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		StartedAt   string `json:"started_at"`
+		CompletedAt string `json:"completed_at"`
+		Phase       string `json:"phase"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	execution.Phase = raw.Phase
+	for _, field := range []struct {
+		value string
+		time  *time.Time
+	}{{raw.StartedAt, &execution.StartedAt}, {raw.CompletedAt, &execution.CompletedAt}} {
+		if field.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, field.value)
+		if err != nil {
+			return err
+		}
+		*field.time = parsed
+	}
+	return nil
+}
+
+// ReleaseChart is the chart of a release.
+type ReleaseChart struct {
+	Metadata ChartMetadata `json:"metadata"`
+}
+
+// ChartMetadata is the metadata of a chart, from its Chart.yaml.
+type ChartMetadata struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	AppVersion  string `json:"appVersion"`
+	Description string `json:"description"`
+	APIVersion  string `json:"apiVersion"`
+	Type        string `json:"type"`
+}
+
+// ReleaseRevision is a revision of a release, as returned by `helm history -o json`.
+type ReleaseRevision struct {
+	Revision    int       `json:"revision"`
+	Updated     time.Time `json:"updated"`
+	Status      string    `json:"status"`
+	Chart       string    `json:"chart"` // The chart name and version, e.g., nginx-1.2.3
+	AppVersion  string    `json:"app_version"`
+	Description string    `json:"description"`
+}
+
+// ListedRelease is a release, as returned by `helm list -o json`.
+type ListedRelease struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Revision   int    `json:"revision,string"`
+	Updated    string `json:"updated"` // helm formats it with the Go default format for times, e.g., 2021-06-01 12:00:00.123 +0000 UTC
+	Status     string `json:"status"`
+	Chart      string `json:"chart"` // The chart name and version, e.g., nginx-1.2.3
+	AppVersion string `json:"app_version"`
+}
+
+// GetRelease runs `helm status` and `helm get manifest` to return the release with the given name, including the
+// deployed manifest. This will fail the test if there is an error.
+func GetRelease(t testing.TestingT, options *Options, releaseName string) *Release {
+	release, err := GetReleaseE(t, options, releaseName)
+	require.NoError(t, err)
+	return release
+}
+
+// GetReleaseE runs `helm status` and `helm get manifest` to return the release with the given name, including the
+// deployed manifest.
+func GetReleaseE(t testing.TestingT, options *Options, releaseName string) (*Release, error) {
+	release, err := GetReleaseStatusE(t, options, releaseName)
+	if err != nil {
+		return nil, err
+	}
+	release.Manifest, err = GetReleaseManifestE(t, options, releaseName)
+	if err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// GetReleaseStatus runs `helm status` to return the release with the given name, with the status of its current
+// revision. This will fail the test if there is an error.
+func GetReleaseStatus(t testing.TestingT, options *Options, releaseName string) *Release {
+	release, err := GetReleaseStatusE(t, options, releaseName)
+	require.NoError(t, err)
+	return release
+}
+
+// GetReleaseStatusE runs `helm status` to return the release with the given name, with the status of its current
+// revision.
+func GetReleaseStatusE(t testing.TestingT, options *Options, releaseName string) (*Release, error) {
+	out, err := RunHelmCommandAndGetStdOutE(t, options, "status", releaseName, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	var release Release
+	if err := json.Unmarshal([]byte(out), &release); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return &release, nil
+}
+
+// GetReleaseHistory runs `helm history` to return the revisions of the release with the given name, oldest first.
+// This will fail the test if there is an error.
+func GetReleaseHistory(t testing.TestingT, options *Options, releaseName string) []ReleaseRevision {
+	history, err := GetReleaseHistoryE(t, options, releaseName)
+	require.NoError(t, err)
+	return history
+}
+
+// GetReleaseHistoryE runs `helm history` to return the revisions of the release with the given name, oldest first.
+func GetReleaseHistoryE(t testing.TestingT, options *Options, releaseName string) ([]ReleaseRevision, error) {
+	out, err := RunHelmCommandAndGetStdOutE(t, options, "history", releaseName, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	history := []ReleaseRevision{}
+	if err := json.Unmarshal([]byte(out), &history); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return history, nil
+}
+
+// GetReleaseValues runs `helm get values` to return the values supplied by the user for the current revision of the
+// release with the given name. If allValues is true, all the computed values are returned, including the defaults
+// from the chart. This will fail the test if there is an error.
+func GetReleaseValues(t testing.TestingT, options *Options, releaseName string, allValues bool) map[string]interface{} {
+	values, err := GetReleaseValuesE(t, options, releaseName, allValues)
+	require.NoError(t, err)
+	return values
+}
+
+// GetReleaseValuesE runs `helm get values` to return the values supplied by the user for the current revision of the
+// release with the given name. If allValues is true, all the computed values are returned, including the defaults
+// from the chart.
+func GetReleaseValuesE(t testing.TestingT, options *Options, releaseName string, allValues bool) (map[string]interface{}, error) {
+	args := []string{"values", releaseName, "--output", "json"}
+	if allValues {
+		args = append(args, "--all")
+	}
+	out, err := RunHelmCommandAndGetStdOutE(t, options, "get", args...)
+	if err != nil {
+		return nil, err
+	}
+	// helm outputs null when no values were supplied
+	values := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(out), &values); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return values, nil
+}
+
+// GetReleaseManifest runs `helm get manifest` to return the manifests deployed by the current revision of the release
+// with the given name. Use UnmarshalK8SYamlObjects to decode them. This will fail the test if there is an error.
+func GetReleaseManifest(t testing.TestingT, options *Options, releaseName string) string {
+	manifest, err := GetReleaseManifestE(t, options, releaseName)
+	require.NoError(t, err)
+	return manifest
+}
+
+// GetReleaseManifestE runs `helm get manifest` to return the manifests deployed by the current revision of the release
+// with the given name. Use UnmarshalK8SYamlObjectsE to decode them.
+func GetReleaseManifestE(t testing.TestingT, options *Options, releaseName string) (string, error) {
+	return RunHelmCommandAndGetStdOutE(t, options, "get", "manifest", releaseName)
+}
+
+// ListReleases runs `helm list` to return the releases in the namespace of the options, or in all the namespaces if
+// options.ExtraArgs["list"] has `--all-namespaces`. By default, helm only lists the deployed and failed releases: pass
+// `--all` in options.ExtraArgs["list"] to list all of them. This will fail the test if there is an error.
+func ListReleases(t testing.TestingT, options *Options) []ListedRelease {
+	releases, err := ListReleasesE(t, options)
+	require.NoError(t, err)
+	return releases
+}
+
+// ListReleasesE runs `helm list` to return the releases in the namespace of the options, or in all the namespaces if
+// options.ExtraArgs["list"] has `--all-namespaces`. By default, helm only lists the deployed and failed releases: pass
+// `--all` in options.ExtraArgs["list"] to list all of them.
+func ListReleasesE(t testing.TestingT, options *Options) ([]ListedRelease, error) {
+	args := []string{"--output", "json"}
+	if options.ExtraArgs != nil {
+		if listArgs, ok := options.ExtraArgs["list"]; ok {
+			args = append(args, listArgs...)
+		}
+	}
+	out, err := RunHelmCommandAndGetStdOutE(t, options, "list", args...)
+	if err != nil {
+		return nil, err
+	}
+	releases := []ListedRelease{}
+	if err := json.Unmarshal([]byte(out), &releases); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return releases, nil
+}
@@ -0,0 +1,127 @@
+package helm
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHelm writes a script that prints canned `helm` outputs for the given subcommands, so that the parsing of the
+// outputs can be tested without a cluster.
+func fakeHelm(t *testing.T, outputs map[string]string) *Options {
+	script := "#!/bin/sh\ncase \"$1 $2\" in\n"
+	for command, output := range outputs {
+		script += "\"" + command + "\"*) cat <<'EOF'\n" + output + "\nEOF\n;;\n"
+	}
+	script += "*) echo \"unexpected command: $*\" >&2; exit 1;;\nesac\n"
+
+	path := filepath.Join(t.TempDir(), "helm")
+	require.NoError(t, ioutil.WriteFile(path, []byte(script), 0755))
+	return &Options{Executable: path, Logger: logger.Discard}
+}
+
+func TestGetRelease(t *testing.T) {
+	t.Parallel()
+
+	options := fakeHelm(t, map[string]string{
+		"status nginx": `{"name":"nginx","info":{"first_deployed":"2021-06-01T12:00:00.123456Z","last_deployed":"2021-06-01T12:05:00Z","deleted":"","description":"Rollback to 1","status":"deployed","notes":"Visit http://nginx"},"chart":{"metadata":{"name":"nginx","version":"1.2.3","description":"NGINX","apiVersion":"v2","appVersion":"1.21.0","type":"application"}},"config":{"replicaCount":2},"version":3,"namespace":"default"}`,
+		"get manifest": "---\nkind: Service\nmetadata:\n  name: nginx",
+	})
+
+	release := GetRelease(t, options, "nginx")
+	assert.Equal(t, "nginx", release.Name)
+	assert.Equal(t, 3, release.Revision)
+	assert.Equal(t, "deployed", release.Info.Status)
+	assert.Equal(t, "Rollback to 1", release.Info.Description)
+	assert.Equal(t, "1.2.3", release.Chart.Metadata.Version)
+	assert.Equal(t, "1.21.0", release.Chart.Metadata.AppVersion)
+	assert.Equal(t, map[string]interface{}{"replicaCount": 2.0}, release.Config)
+	assert.Equal(t, 2021, release.Info.FirstDeployed.Year())
+	assert.NotNil(t, UnmarshalK8SYamlObjects(t, release.Manifest).Lookup("Service", "", "nginx"))
+}
+
+func TestGetReleaseHistory(t *testing.T) {
+	t.Parallel()
+
+	options := fakeHelm(t, map[string]string{
+		"history nginx": `[{"revision":1,"updated":"2021-06-01T12:00:00.123456Z","status":"superseded","chart":"nginx-1.2.3","app_version":"1.21.0","description":"Install complete"},{"revision":2,"updated":"2021-06-01T12:05:00Z","status":"deployed","chart":"nginx-1.2.4","app_version":"1.21.1","description":"Upgrade complete"}]`,
+	})
+
+	history := GetReleaseHistory(t, options, "nginx")
+	require.Len(t, history, 2)
+	assert.Equal(t, ReleaseRevision{Revision: 2, Updated: history[1].Updated, Status: "deployed", Chart: "nginx-1.2.4", AppVersion: "1.21.1", Description: "Upgrade complete"}, history[1])
+	assert.True(t, history[0].Updated.Before(history[1].Updated))
+}
+
+func TestGetReleaseValuesAndListReleases(t *testing.T) {
+	t.Parallel()
+
+	options := fakeHelm(t, map[string]string{
+		"get values":    `null`,
+		"list --output": `[{"name":"nginx","namespace":"default","revision":"2","updated":"2021-06-01 12:05:00.123 +0000 UTC","status":"deployed","chart":"nginx-1.2.4","app_version":"1.21.1"}]`,
+	})
+
+	assert.Equal(t, map[string]interface{}{}, GetReleaseValues(t, options, "nginx", false))
+	assert.Equal(t, []ListedRelease{{
+		Name:       "nginx",
+		Namespace:  "default",
+		Revision:   2,
+		Updated:    "2021-06-01 12:05:00.123 +0000 UTC",
+		Status:     "deployed",
+		Chart:      "nginx-1.2.4",
+		AppVersion: "1.21.1",
+	}}, ListReleases(t, options))
+}
+
+func TestRunTests(t *testing.T) {
+	t.Parallel()
+
+	// The hooks of this run start after RunTestsE is called, while the ones of a previous run are ignored
+	now := time.Now().UTC().Add(time.Minute)
+	timestamp := func(offset time.Duration) string { return now.Add(offset).Format(time.RFC3339Nano) }
+	hooks := `[
+		{"name":"nginx-test-connection","kind":"Pod","path":"nginx/templates/tests/test-connection.yaml","events":["test"],"last_run":{"started_at":"` + timestamp(0) + `","completed_at":"` + timestamp(5*time.Second) + `","phase":"Succeeded"}},
+		{"name":"nginx-test-config","kind":"Job","path":"nginx/templates/tests/test-config.yaml","events":["test"],"last_run":{"started_at":"` + timestamp(5*time.Second) + `","completed_at":"` + timestamp(10*time.Second) + `","phase":"Failed"}},
+		{"name":"nginx-test-filtered","kind":"Pod","path":"nginx/templates/tests/test-filtered.yaml","events":["test"],"last_run":{"started_at":"","completed_at":"","phase":""}},
+		{"name":"nginx-test-previous-run","kind":"Pod","path":"nginx/templates/tests/test-previous-run.yaml","events":["test"],"last_run":{"started_at":"2021-06-01T12:00:00Z","completed_at":"2021-06-01T12:00:05Z","phase":"Failed"}},
+		{"name":"nginx-migrate","kind":"Job","path":"nginx/templates/migrate.yaml","events":["pre-upgrade"],"last_run":{"started_at":"2021-06-01T11:00:00Z","completed_at":"2021-06-01T11:00:05Z","phase":"Succeeded"}}
+	]`
+	options := fakeHelm(t, map[string]string{
+		"test nginx":   `Error: job nginx-test-config failed`,
+		"status nginx": `{"name":"nginx","namespace":"default","version":1,"info":{"status":"deployed"},"hooks":` + hooks + `}`,
+	})
+
+	results, err := RunTestsE(t, options, "nginx")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 test hook(s) of release nginx failed")
+	assert.Contains(t, err.Error(), "=== Job nginx-test-config (phase Failed)")
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "nginx-test-connection", results[0].Name)
+	assert.False(t, results[0].Failed())
+	assert.Equal(t, 5*time.Second, results[0].CompletedAt.Sub(results[0].StartedAt))
+	assert.True(t, results[1].Failed())
+}
+
+func TestTestHooksFailedErrorIncludesLogs(t *testing.T) {
+	t.Parallel()
+
+	err := TestHooksFailedError{ReleaseName: "nginx", Failed: []TestHookResult{{
+		Name:  "nginx-test-connection",
+		Kind:  "Pod",
+		Phase: "Failed",
+		Logs:  map[string]string{"wget": "wget: can't connect to remote host\n", "init": "waiting\n"},
+	}}}
+	assert.Equal(t, `1 test hook(s) of release nginx failed:
+
+=== Pod nginx-test-connection (phase Failed)
+--- logs of container init:
+waiting
+--- logs of container wget:
+wget: can't connect to remote host`, err.Error())
+}
@@ -0,0 +1,166 @@
+package helm
+
+import (
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// testHookEvent is the event of the hooks run by `helm test`.
+const testHookEvent = "test"
+
+// TestHookResult is the result of a test hook run by `helm test`.
+type TestHookResult struct {
+	Name        string
+	Kind        string
+	Phase       string // e.g., Succeeded, Failed
+	StartedAt   time.Time
+	CompletedAt time.Time
+	// Logs are the logs of the containers of the hook pod by container name, which are only fetched for the hooks that
+	// failed. For Job hooks, they are the logs of the containers of all the pods of the job, by <pod>/<container>.
+	Logs map[string]string
+}
+
+// Failed returns true if the test hook didn't succeed.
+func (result TestHookResult) Failed() bool {
+	return result.Phase != "Succeeded"
+}
+
+// RunTests runs `helm test` to run the test hooks of the release with the given name, and returns the result of each
+// test hook. When a test hook fails, the logs of its pod are fetched and included in the failure of the test, so that
+// you can see what went wrong without going to the cluster. This will fail the test if any test hook fails.
+func RunTests(t testing.TestingT, options *Options, releaseName string) []TestHookResult {
+	results, err := RunTestsE(t, options, releaseName)
+	require.NoError(t, err)
+	return results
+}
+
+// RunTestsE runs `helm test` to run the test hooks of the release with the given name, and returns the result of each
+// test hook. When a test hook fails, the logs of its pod are fetched and a TestHooksFailedError is returned with them.
+// Pass `--filter` in options.ExtraArgs["test"] to only run some of the test hooks.
+func RunTestsE(t testing.TestingT, options *Options, releaseName string) ([]TestHookResult, error) {
+	args := []string{}
+	if options.ExtraArgs != nil {
+		if testArgs, ok := options.ExtraArgs["test"]; ok {
+			args = append(args, testArgs...)
+		}
+	}
+	args = append(args, releaseName)
+
+	// helm records when each hook starts, so the hooks that started before this are left over from previous runs (e.g.,
+	// excluded with --filter this time)
+	startedAt := time.Now()
+
+	// helm fails when any test hook fails, but still records the results of all the test hooks in the release, so the
+	// results are read back from the release either way.
+	_, testErr := RunHelmCommandAndGetOutputE(t, options, "test", args...)
+
+	release, err := GetReleaseStatusE(t, options, releaseName)
+	if err != nil {
+		if testErr != nil {
+			return nil, testErr
+		}
+		return nil, err
+	}
+
+	results := []TestHookResult{}
+	failed := []TestHookResult{}
+	for _, hook := range release.Hooks {
+		// The test hooks that never ran have no phase, and the ones that didn't run this time (e.g., excluded with
+		// --filter) still have the results of their last run
+		if !isTestHook(hook) || hook.LastRun.Phase == "" || hook.LastRun.StartedAt.Before(startedAt.Truncate(time.Second)) {
+			continue
+		}
+		result := TestHookResult{
+			Name:        hook.Name,
+			Kind:        hook.Kind,
+			Phase:       hook.LastRun.Phase,
+			StartedAt:   hook.LastRun.StartedAt,
+			CompletedAt: hook.LastRun.CompletedAt,
+		}
+		if result.Failed() {
+			result.Logs = getTestHookLogs(t, options, release.Namespace, hook)
+			failed = append(failed, result)
+		}
+		results = append(results, result)
+	}
+
+	if len(failed) > 0 {
+		return results, errors.WithStackTrace(TestHooksFailedError{ReleaseName: releaseName, Failed: failed})
+	}
+	if testErr != nil {
+		return results, testErr
+	}
+	return results, nil
+}
+
+// isTestHook returns true if the given hook is run by `helm test`.
+func isTestHook(hook ReleaseHook) bool {
+	for _, event := range hook.Events {
+		// Helm 2 charts use the test-success and test-failure events
+		if event == testHookEvent || event == "test-success" || event == "test-failure" {
+			return true
+		}
+	}
+	return false
+}
+
+// getTestHookLogs returns the logs of the containers of the pod of the given test hook, which is either the hook
+// itself, for Pod hooks, or the pods of the hook, for Job hooks. The logs are best effort: the pods may already be
+// deleted by the hook-delete-policy, in which case there are no logs.
+func getTestHookLogs(t testing.TestingT, options *Options, namespace string, hook ReleaseHook) map[string]string {
+	kubectlOptions := k8s.NewKubectlOptions("", "", namespace)
+	if options.KubectlOptions != nil {
+		// Copy the options to keep the auth settings and environment, e.g. for the exec plugins of the kubeconfig
+		copied := *options.KubectlOptions
+		copied.Namespace = namespace
+		kubectlOptions = &copied
+	}
+
+	switch hook.Kind {
+	case "Pod":
+		pod, err := k8s.GetPodE(t, kubectlOptions, hook.Name)
+		if err != nil {
+			logger.Logf(t, "Could not get the pod of the failed test hook %s: %v", hook.Name, err)
+			return nil
+		}
+		return getTestHookPodLogs(t, kubectlOptions, pod, "")
+	case "Job":
+		pods, err := k8s.ListPodsE(t, kubectlOptions, metav1.ListOptions{LabelSelector: "job-name=" + hook.Name})
+		if err != nil {
+			logger.Logf(t, "Could not list the pods of the failed test hook %s: %v", hook.Name, err)
+			return nil
+		}
+		logs := map[string]string{}
+		for i := range pods {
+			for container, containerLogs := range getTestHookPodLogs(t, kubectlOptions, &pods[i], pods[i].Name+"/") {
+				logs[container] = containerLogs
+			}
+		}
+		return logs
+	default:
+		return nil
+	}
+}
+
+// getTestHookPodLogs returns the logs of each container of the given test hook pod, by container name with the given
+// prefix.
+func getTestHookPodLogs(t testing.TestingT, kubectlOptions *k8s.KubectlOptions, pod *corev1.Pod, prefix string) map[string]string {
+	logs := map[string]string{}
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		containerLogs, err := k8s.GetPodLogsE(t, kubectlOptions, pod.Name, container.Name)
+		if err != nil {
+			logger.Logf(t, "Could not get the logs of container %s of the failed test hook pod %s: %v", container.Name, pod.Name, err)
+			continue
+		}
+		logs[prefix+container.Name] = containerLogs
+	}
+	return logs
+}
@@ -0,0 +1,196 @@
+package helm
+
+import (
+	"github.com/gruntwork-io/go-commons/errors"
+	goversion "github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// RemovedAPI is a Kubernetes API group/version of a kind that is no longer served from a Kubernetes release on.
+type RemovedAPI struct {
+	APIVersion  string // e.g., extensions/v1beta1
+	Kind        string // e.g., Ingress
+	RemovedIn   string // The Kubernetes release that no longer serves the API, e.g., 1.22
+	Replacement string // The API group/version to use instead, if any, e.g., networking.k8s.io/v1
+}
+
+// RemovedAPIs are the API group/versions removed from Kubernetes, as listed in the deprecated API migration guide
+// (https://kubernetes.io/docs/reference/using-api/deprecation-guide/). You can append to this list, e.g. for the APIs
+// of CRDs that you use.
+var RemovedAPIs = []RemovedAPI{
+	{"extensions/v1beta1", "DaemonSet", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "Deployment", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", "1.16", "policy/v1beta1"},
+	{"apps/v1beta1", "Deployment", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "1.16", "apps/v1"},
+	{"apps/v1beta1", "ReplicaSet", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "1.16", "apps/v1"},
+
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "1.22", "apiregistration.k8s.io/v1"},
+	{"authentication.k8s.io/v1beta1", "TokenReview", "1.22", "authentication.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "LocalSubjectAccessReview", "1.22", "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SelfSubjectAccessReview", "1.22", "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SubjectAccessReview", "1.22", "authorization.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.22", "coordination.k8s.io/v1"},
+	{"extensions/v1beta1", "Ingress", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.22", "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "1.22", "storage.k8s.io/v1"},
+
+	{"batch/v1beta1", "CronJob", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.25", ""},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "1.25", "node.k8s.io/v1"},
+
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.26", "autoscaling/v2"},
+
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "1.27", "storage.k8s.io/v1"},
+
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// RemovedAPIUsage is a rendered object that uses an API removed in the Kubernetes version it was rendered for.
+type RemovedAPIUsage struct {
+	Object RenderedObjectKey
+	API    RemovedAPI
+}
+
+// RenderTemplateForKubeVersions renders the chart in the given folder once for each of the given Kubernetes versions,
+// same as RenderTemplateAsObjects with options.KubeVersion set to the version, so that the templates that branch on
+// .Capabilities.KubeVersion are rendered the way they would be on each version. Returns the rendered objects by
+// Kubernetes version. This will fail the test if there is an error rendering or decoding the templates.
+func RenderTemplateForKubeVersions(t testing.TestingT, options *Options, chartDir string, releaseName string, kubeVersions []string, templateFiles []string, extraHelmArgs ...string) map[string]*RenderedObjects {
+	objects, err := RenderTemplateForKubeVersionsE(t, options, chartDir, releaseName, kubeVersions, templateFiles, extraHelmArgs...)
+	require.NoError(t, err)
+	return objects
+}
+
+// RenderTemplateForKubeVersionsE renders the chart in the given folder once for each of the given Kubernetes
+// versions, same as RenderTemplateAsObjectsE with options.KubeVersion set to the version, so that the templates that
+// branch on .Capabilities.KubeVersion are rendered the way they would be on each version. Returns the rendered objects
+// by Kubernetes version. Note that helm doesn't adjust .Capabilities.APIVersions to the version: set
+// options.APIVersions for the templates that branch on it.
+func RenderTemplateForKubeVersionsE(t testing.TestingT, options *Options, chartDir string, releaseName string, kubeVersions []string, templateFiles []string, extraHelmArgs ...string) (map[string]*RenderedObjects, error) {
+	out := map[string]*RenderedObjects{}
+	for _, kubeVersion := range kubeVersions {
+		versionOptions := *options
+		versionOptions.KubeVersion = kubeVersion
+		objects, err := RenderTemplateAsObjectsE(t, &versionOptions, chartDir, releaseName, templateFiles, extraHelmArgs...)
+		if err != nil {
+			return nil, err
+		}
+		out[kubeVersion] = objects
+	}
+	return out, nil
+}
+
+// CheckRemovedAPIs renders the chart in the given folder for each of the given Kubernetes versions (e.g., 1.21, 1.22
+// and 1.25), and checks that no rendered object uses an API group/version listed in RemovedAPIs as removed in that
+// version. This catches the use of deprecated APIs before upgrading the clusters. This will fail the test if there is
+// an error or if any rendered object uses a removed API.
+func CheckRemovedAPIs(t testing.TestingT, options *Options, chartDir string, releaseName string, kubeVersions []string) {
+	require.NoError(t, CheckRemovedAPIsE(t, options, chartDir, releaseName, kubeVersions))
+}
+
+// CheckRemovedAPIsE renders the chart in the given folder for each of the given Kubernetes versions (e.g., 1.21, 1.22
+// and 1.25), and checks that no rendered object uses an API group/version listed in RemovedAPIs as removed in that
+// version. Returns a RemovedAPIsError listing the offending objects by Kubernetes version if any.
+func CheckRemovedAPIsE(t testing.TestingT, options *Options, chartDir string, releaseName string, kubeVersions []string) error {
+	rendered, err := RenderTemplateForKubeVersionsE(t, options, chartDir, releaseName, kubeVersions, nil)
+	if err != nil {
+		return err
+	}
+
+	usages := map[string][]RemovedAPIUsage{}
+	for kubeVersion, objects := range rendered {
+		versionUsages, err := FindRemovedAPIsE(t, objects, kubeVersion)
+		if err != nil {
+			return err
+		}
+		if len(versionUsages) > 0 {
+			usages[kubeVersion] = versionUsages
+		}
+	}
+	if len(usages) > 0 {
+		return errors.WithStackTrace(RemovedAPIsError{ChartDir: chartDir, Usages: usages})
+	}
+	return nil
+}
+
+// FindRemovedAPIs returns the given rendered objects that use an API group/version listed in RemovedAPIs as removed
+// in the given Kubernetes version (e.g., 1.22 or v1.22.3), sorted by kind, namespace and name. This will fail the test
+// if the Kubernetes version can't be parsed.
+func FindRemovedAPIs(t testing.TestingT, objects *RenderedObjects, kubeVersion string) []RemovedAPIUsage {
+	usages, err := FindRemovedAPIsE(t, objects, kubeVersion)
+	require.NoError(t, err)
+	return usages
+}
+
+// FindRemovedAPIsE returns the given rendered objects that use an API group/version listed in RemovedAPIs as removed
+// in the given Kubernetes version (e.g., 1.22 or v1.22.3), sorted by kind, namespace and name.
+func FindRemovedAPIsE(t testing.TestingT, objects *RenderedObjects, kubeVersion string) ([]RemovedAPIUsage, error) {
+	version, err := goversion.NewVersion(kubeVersion)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	usages := []RemovedAPIUsage{}
+	for _, key := range objects.Keys() {
+		object := objects.Lookup(key.Kind, key.Namespace, key.Name)
+		for _, removed := range RemovedAPIs {
+			if removed.Kind != key.Kind || removed.APIVersion != object.GetAPIVersion() {
+				continue
+			}
+			removedIn, err := goversion.NewVersion(removed.RemovedIn)
+			if err != nil {
+				return nil, errors.WithStackTrace(err)
+			}
+			// Only the major and minor versions matter, so that e.g. 1.22.0-gke.1 counts as 1.22
+			if !isOlderKubeRelease(version, removedIn) {
+				usages = append(usages, RemovedAPIUsage{Object: key, API: removed})
+			}
+		}
+	}
+	return usages, nil
+}
+
+// isOlderKubeRelease returns true if the major and minor versions of the given version are less than the ones of the
+// given release.
+func isOlderKubeRelease(version *goversion.Version, release *goversion.Version) bool {
+	versionSegments := version.Segments()
+	releaseSegments := release.Segments()
+	for i := 0; i < 2; i++ {
+		if versionSegments[i] != releaseSegments[i] {
+			return versionSegments[i] < releaseSegments[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const renderedWithRemovedAPIs = `---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: nginx
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cleanup
+  namespace: jobs
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+`
+
+func TestFindRemovedAPIs(t *testing.T) {
+	t.Parallel()
+
+	objects := UnmarshalK8SYamlObjects(t, renderedWithRemovedAPIs)
+
+	assert.Empty(t, FindRemovedAPIs(t, objects, "1.21.5"))
+
+	usages := FindRemovedAPIs(t, objects, "v1.22.0-gke.1")
+	require.Len(t, usages, 1)
+	assert.Equal(t, RenderedObjectKey{Kind: "Ingress", Name: "nginx"}, usages[0].Object)
+	assert.Equal(t, "networking.k8s.io/v1", usages[0].API.Replacement)
+
+	assert.Len(t, FindRemovedAPIs(t, objects, "1.25"), 2)
+
+	_, err := FindRemovedAPIsE(t, objects, "not-a-version")
+	require.Error(t, err)
+}
+
+func TestCheckRemovedAPIs(t *testing.T) {
+	t.Parallel()
+
+	options := fakeHelm(t, map[string]string{
+		"dependency build":        "",
+		"template --kube-version": renderedWithRemovedAPIs,
+	})
+
+	require.NoError(t, CheckRemovedAPIsE(t, options, t.TempDir(), "nginx", []string{"1.20", "1.21"}))
+
+	err := CheckRemovedAPIsE(t, options, "/", "nginx", []string{"1.21", "1.22", "1.25"})
+	require.Error(t, err)
+	removedErr, ok := errors.Unwrap(err).(RemovedAPIsError)
+	require.True(t, ok)
+	assert.Len(t, removedErr.Usages, 2)
+	assert.Equal(t, `Chart / renders objects with removed APIs:
+Kubernetes 1.22:
+  - Ingress nginx uses networking.k8s.io/v1beta1, removed in 1.22 (use networking.k8s.io/v1 instead)
+Kubernetes 1.25:
+  - CronJob cleanup in namespace jobs uses batch/v1beta1, removed in 1.25 (use batch/v1 instead)
+  - Ingress nginx uses networking.k8s.io/v1beta1, removed in 1.22 (use networking.k8s.io/v1 instead)`, removedErr.Error())
+}
+
+func TestRemovedAPIsErrorSortsKubeVersions(t *testing.T) {
+	t.Parallel()
+
+	usage := RemovedAPIUsage{
+		Object: RenderedObjectKey{Kind: "Ingress", Name: "nginx"},
+		API:    RemovedAPI{Kind: "Ingress", APIVersion: "networking.k8s.io/v1beta1", RemovedIn: "1.22"},
+	}
+	err := RemovedAPIsError{ChartDir: "/", Usages: map[string][]RemovedAPIUsage{"1.25": {usage}, "1.9": {usage}}}
+	assert.Equal(t, `Chart / renders objects with removed APIs:
+Kubernetes 1.9:
+  - Ingress nginx uses networking.k8s.io/v1beta1, removed in 1.22
+Kubernetes 1.25:
+  - Ingress nginx uses networking.k8s.io/v1beta1, removed in 1.22`, err.Error())
+}
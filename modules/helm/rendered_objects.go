@@ -0,0 +1,311 @@
+package helm
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// RenderedObjectKey identifies a rendered object by its kind, namespace and name. The namespace is the one set in the
+// rendered manifest, which is empty for the many charts that leave it to the namespace of the release.
+type RenderedObjectKey struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// RenderedObjects are the objects rendered from a chart, decoded from all the YAML documents of the rendered templates.
+type RenderedObjects struct {
+	// Objects are the rendered objects, in the order they were rendered.
+	Objects []*unstructured.Unstructured
+
+	index map[RenderedObjectKey]*unstructured.Unstructured
+}
+
+// RenderTemplateAsObjects runs `helm template` to render the template given the provided options, same as
+// RenderTemplate, and decodes every rendered manifest, so that you can look the objects up by kind and name. For
+// example:
+//
+// objects := helm.RenderTemplateAsObjects(t, options, chartDir, "nginx", nil)
+// deployment := objects.GetDeployment(t, "nginx")
+//
+// This function will fail the test if there is an error rendering or decoding the templates.
+func RenderTemplateAsObjects(t testing.TestingT, options *Options, chartDir string, releaseName string, templateFiles []string, extraHelmArgs ...string) *RenderedObjects {
+	objects, err := RenderTemplateAsObjectsE(t, options, chartDir, releaseName, templateFiles, extraHelmArgs...)
+	require.NoError(t, err)
+	return objects
+}
+
+// RenderTemplateAsObjectsE runs `helm template` to render the template given the provided options, same as
+// RenderTemplateE, and decodes every rendered manifest, so that you can look the objects up by kind and name.
+func RenderTemplateAsObjectsE(t testing.TestingT, options *Options, chartDir string, releaseName string, templateFiles []string, extraHelmArgs ...string) (*RenderedObjects, error) {
+	out, err := RenderTemplateE(t, options, chartDir, releaseName, templateFiles, extraHelmArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalK8SYamlObjectsE(t, out)
+}
+
+// UnmarshalK8SYamlObjects is the same as UnmarshalK8SYamlObjectsE, but will fail the test if there is an error.
+func UnmarshalK8SYamlObjects(t testing.TestingT, yamlData string) *RenderedObjects {
+	objects, err := UnmarshalK8SYamlObjectsE(t, yamlData)
+	require.NoError(t, err)
+	return objects
+}
+
+// UnmarshalK8SYamlObjectsE decodes all the documents of the given multi-document YAML (e.g., the output of
+// RenderTemplate) into unstructured objects. Empty documents, such as the ones rendered from templates that are
+// disabled by the values, are ignored, and the items of List objects are decoded as separate objects.
+func UnmarshalK8SYamlObjectsE(t testing.TestingT, yamlData string) (*RenderedObjects, error) {
+	objects := &RenderedObjects{index: map[RenderedObjectKey]*unstructured.Unstructured{}}
+
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(strings.NewReader(yamlData)))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+
+		// NOTE: the unstructured objects can only decode json, so we will first convert the yaml to json
+		jsonData, err := yaml.YAMLToJSON(document)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		if len(bytes.TrimSpace(jsonData)) == 0 || string(bytes.TrimSpace(jsonData)) == "null" {
+			continue
+		}
+
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(jsonData); err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+
+		if object.IsList() {
+			list, err := object.ToList()
+			if err != nil {
+				return nil, errors.WithStackTrace(err)
+			}
+			for i := range list.Items {
+				if err := objects.add(&list.Items[i]); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := objects.add(object); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// add adds the given object, failing if an object with the same kind, namespace and name was already rendered.
+func (objects *RenderedObjects) add(object *unstructured.Unstructured) error {
+	key := RenderedObjectKey{Kind: object.GetKind(), Namespace: object.GetNamespace(), Name: object.GetName()}
+	if _, exists := objects.index[key]; exists {
+		return errors.WithStackTrace(DuplicateRenderedObjectError{Key: key})
+	}
+	objects.index[key] = object
+	objects.Objects = append(objects.Objects, object)
+	return nil
+}
+
+// Keys returns the kind, namespace and name of all the rendered objects, sorted.
+func (objects *RenderedObjects) Keys() []RenderedObjectKey {
+	keys := []RenderedObjectKey{}
+	for key := range objects.index {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Kind != keys[j].Kind {
+			return keys[i].Kind < keys[j].Kind
+		}
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+// Lookup returns the rendered object with the given kind, namespace and name, or nil if there is none.
+func (objects *RenderedObjects) Lookup(kind string, namespace string, name string) *unstructured.Unstructured {
+	return objects.index[RenderedObjectKey{Kind: kind, Namespace: namespace, Name: name}]
+}
+
+// OfKind returns all the rendered objects of the given kind, in the order they were rendered.
+func (objects *RenderedObjects) OfKind(kind string) []*unstructured.Unstructured {
+	matches := []*unstructured.Unstructured{}
+	for _, object := range objects.Objects {
+		if object.GetKind() == kind {
+			matches = append(matches, object)
+		}
+	}
+	return matches
+}
+
+// GetObject is the same as GetObjectE, but will fail the test if there is an error.
+func (objects *RenderedObjects) GetObject(t testing.TestingT, kind string, name string, destinationObj interface{}) {
+	require.NoError(t, objects.GetObjectE(t, kind, name, destinationObj))
+}
+
+// GetObjectE finds the rendered object with the given kind and name, in any namespace, and converts it into the given
+// client-go struct. For example:
+//
+// var configMap corev1.ConfigMap
+// objects.GetObjectE(t, "ConfigMap", "nginx-config", &configMap)
+//
+// Returns an error if there is no such object, or if there is one in more than one namespace.
+func (objects *RenderedObjects) GetObjectE(t testing.TestingT, kind string, name string, destinationObj interface{}) error {
+	var match *unstructured.Unstructured
+	for _, object := range objects.Objects {
+		if object.GetKind() != kind || object.GetName() != name {
+			continue
+		}
+		if match != nil {
+			return errors.WithStackTrace(AmbiguousRenderedObjectError{Kind: kind, Name: name})
+		}
+		match = object
+	}
+	if match == nil {
+		return errors.WithStackTrace(RenderedObjectNotFoundError{Kind: kind, Name: name})
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(match.Object, destinationObj); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
+
+// RuntimeObjects returns the rendered objects as runtime.Objects, in the order they were rendered. The objects of the
+// kinds known to client-go are converted into the corresponding structs (e.g., *appsv1.Deployment), while the others
+// (e.g., custom resources) are returned as *unstructured.Unstructured.
+func (objects *RenderedObjects) RuntimeObjects(t testing.TestingT) []runtime.Object {
+	runtimeObjects, err := objects.RuntimeObjectsE(t)
+	require.NoError(t, err)
+	return runtimeObjects
+}
+
+// RuntimeObjectsE returns the rendered objects as runtime.Objects, in the order they were rendered. The objects of the
+// kinds known to client-go are converted into the corresponding structs (e.g., *appsv1.Deployment), while the others
+// (e.g., custom resources) are returned as *unstructured.Unstructured.
+func (objects *RenderedObjects) RuntimeObjectsE(t testing.TestingT) ([]runtime.Object, error) {
+	runtimeObjects := []runtime.Object{}
+	for _, object := range objects.Objects {
+		typed, err := scheme.Scheme.New(object.GroupVersionKind())
+		if runtime.IsNotRegisteredError(err) {
+			runtimeObjects = append(runtimeObjects, object)
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, typed); err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		runtimeObjects = append(runtimeObjects, typed)
+	}
+	return runtimeObjects, nil
+}
+
+// GetDeployment returns the rendered Deployment with the given name. This will fail the test if there is no such
+// Deployment.
+func (objects *RenderedObjects) GetDeployment(t testing.TestingT, name string) *appsv1.Deployment {
+	var deployment appsv1.Deployment
+	objects.GetObject(t, "Deployment", name, &deployment)
+	return &deployment
+}
+
+// GetStatefulSet returns the rendered StatefulSet with the given name. This will fail the test if there is no such
+// StatefulSet.
+func (objects *RenderedObjects) GetStatefulSet(t testing.TestingT, name string) *appsv1.StatefulSet {
+	var statefulSet appsv1.StatefulSet
+	objects.GetObject(t, "StatefulSet", name, &statefulSet)
+	return &statefulSet
+}
+
+// GetDaemonSet returns the rendered DaemonSet with the given name. This will fail the test if there is no such
+// DaemonSet.
+func (objects *RenderedObjects) GetDaemonSet(t testing.TestingT, name string) *appsv1.DaemonSet {
+	var daemonSet appsv1.DaemonSet
+	objects.GetObject(t, "DaemonSet", name, &daemonSet)
+	return &daemonSet
+}
+
+// GetJob returns the rendered Job with the given name. This will fail the test if there is no such Job.
+func (objects *RenderedObjects) GetJob(t testing.TestingT, name string) *batchv1.Job {
+	var job batchv1.Job
+	objects.GetObject(t, "Job", name, &job)
+	return &job
+}
+
+// GetService returns the rendered Service with the given name. This will fail the test if there is no such Service.
+func (objects *RenderedObjects) GetService(t testing.TestingT, name string) *corev1.Service {
+	var service corev1.Service
+	objects.GetObject(t, "Service", name, &service)
+	return &service
+}
+
+// GetConfigMap returns the rendered ConfigMap with the given name. This will fail the test if there is no such
+// ConfigMap.
+func (objects *RenderedObjects) GetConfigMap(t testing.TestingT, name string) *corev1.ConfigMap {
+	var configMap corev1.ConfigMap
+	objects.GetObject(t, "ConfigMap", name, &configMap)
+	return &configMap
+}
+
+// GetSecret returns the rendered Secret with the given name. This will fail the test if there is no such Secret.
+func (objects *RenderedObjects) GetSecret(t testing.TestingT, name string) *corev1.Secret {
+	var secret corev1.Secret
+	objects.GetObject(t, "Secret", name, &secret)
+	return &secret
+}
+
+// GetServiceAccount returns the rendered ServiceAccount with the given name. This will fail the test if there is no
+// such ServiceAccount.
+func (objects *RenderedObjects) GetServiceAccount(t testing.TestingT, name string) *corev1.ServiceAccount {
+	var serviceAccount corev1.ServiceAccount
+	objects.GetObject(t, "ServiceAccount", name, &serviceAccount)
+	return &serviceAccount
+}
+
+// GetIngress returns the rendered Ingress with the given name. This will fail the test if there is no such Ingress.
+func (objects *RenderedObjects) GetIngress(t testing.TestingT, name string) *networkingv1.Ingress {
+	var ingress networkingv1.Ingress
+	objects.GetObject(t, "Ingress", name, &ingress)
+	return &ingress
+}
+
+// GetRole returns the rendered Role with the given name. This will fail the test if there is no such Role.
+func (objects *RenderedObjects) GetRole(t testing.TestingT, name string) *rbacv1.Role {
+	var role rbacv1.Role
+	objects.GetObject(t, "Role", name, &role)
+	return &role
+}
+
+// GetRoleBinding returns the rendered RoleBinding with the given name. This will fail the test if there is no such
+// RoleBinding.
+func (objects *RenderedObjects) GetRoleBinding(t testing.TestingT, name string) *rbacv1.RoleBinding {
+	var roleBinding rbacv1.RoleBinding
+	objects.GetObject(t, "RoleBinding", name, &roleBinding)
+	return &roleBinding
+}
@@ -0,0 +1,124 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const renderedChart = `---
+# Source: nginx/templates/serviceaccount.yaml
+---
+# Source: nginx/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+    - port: 80
+---
+# Source: nginx/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: nginx
+          image: nginx:1.21
+---
+# Source: nginx/templates/config.yaml
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: nginx-config
+      namespace: default
+    data:
+      foo: bar
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: nginx-config
+      namespace: other
+---
+# Source: nginx/templates/monitor.yaml
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: nginx
+`
+
+func TestUnmarshalK8SYamlObjects(t *testing.T) {
+	t.Parallel()
+
+	objects := UnmarshalK8SYamlObjects(t, renderedChart)
+
+	assert.Equal(t, []RenderedObjectKey{
+		{Kind: "ConfigMap", Namespace: "default", Name: "nginx-config"},
+		{Kind: "ConfigMap", Namespace: "other", Name: "nginx-config"},
+		{Kind: "Deployment", Name: "nginx"},
+		{Kind: "Service", Name: "nginx"},
+		{Kind: "ServiceMonitor", Name: "nginx"},
+	}, objects.Keys())
+	assert.Len(t, objects.OfKind("ConfigMap"), 2)
+
+	deployment := objects.GetDeployment(t, "nginx")
+	require.NotNil(t, deployment.Spec.Replicas)
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+	assert.Equal(t, "nginx:1.21", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, int32(80), objects.GetService(t, "nginx").Spec.Ports[0].Port)
+
+	configMap := objects.Lookup("ConfigMap", "default", "nginx-config")
+	require.NotNil(t, configMap)
+	data, _, err := unstructured.NestedStringMap(configMap.Object, "data")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"foo": "bar"}, data)
+	assert.Nil(t, objects.Lookup("ConfigMap", "", "nginx-config"))
+}
+
+func TestRenderedObjectsGetObjectErrors(t *testing.T) {
+	t.Parallel()
+
+	objects := UnmarshalK8SYamlObjects(t, renderedChart)
+
+	var configMap corev1.ConfigMap
+	err := objects.GetObjectE(t, "ConfigMap", "nginx-config", &configMap)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "in different namespaces")
+
+	var statefulSet appsv1.StatefulSet
+	err = objects.GetObjectE(t, "StatefulSet", "nginx", &statefulSet)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Could not find a rendered StatefulSet named nginx")
+}
+
+func TestRenderedObjectsRuntimeObjects(t *testing.T) {
+	t.Parallel()
+
+	runtimeObjects := UnmarshalK8SYamlObjects(t, renderedChart).RuntimeObjects(t)
+
+	require.Len(t, runtimeObjects, 5)
+	assert.IsType(t, &corev1.Service{}, runtimeObjects[0])
+	assert.IsType(t, &appsv1.Deployment{}, runtimeObjects[1])
+	assert.IsType(t, &corev1.ConfigMap{}, runtimeObjects[2])
+	assert.IsType(t, &unstructured.Unstructured{}, runtimeObjects[4])
+}
+
+func TestUnmarshalK8SYamlObjectsDuplicates(t *testing.T) {
+	t.Parallel()
+
+	_, err := UnmarshalK8SYamlObjectsE(t, "kind: Service\nmetadata:\n  name: a\n---\nkind: Service\nmetadata:\n  name: a\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Rendered more than one Service named a")
+}
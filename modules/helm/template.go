@@ -34,8 +34,9 @@ func RenderTemplateE(t testing.TestingT, options *Options, chartDir string, rele
 		return "", errors.WithStackTrace(ChartNotFoundError{chartDir})
 	}
 
-	// check chart dependencies
-	if _, err := RunHelmCommandAndGetOutputE(t, &Options{}, "dependency", "build", chartDir); err != nil {
+	// check chart dependencies, without the namespace and the values that `helm dependency build` doesn't take
+	dependencyOptions := &Options{Executable: options.Executable, EnvVars: options.EnvVars, Logger: options.Logger}
+	if _, err := RunHelmCommandAndGetOutputE(t, dependencyOptions, "dependency", "build", chartDir); err != nil {
 		return "", errors.WithStackTrace(err)
 	}
 
@@ -49,6 +50,12 @@ func RenderTemplateE(t testing.TestingT, options *Options, chartDir string, rele
 	if err != nil {
 		return "", err
 	}
+	if options.KubeVersion != "" {
+		args = append(args, "--kube-version", options.KubeVersion)
+	}
+	for _, apiVersion := range options.APIVersions {
+		args = append(args, "--api-versions", apiVersion)
+	}
 	for _, templateFile := range templateFiles {
 		// validate this is a valid template file
 		absTemplateFile := filepath.Join(absChartDir, templateFile)
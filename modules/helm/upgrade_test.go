@@ -18,6 +18,8 @@ import (
 	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
 	"github.com/gruntwork-io/terratest/modules/k8s"
 	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test that we can install, upgrade, and rollback a remote chart (e.g stable/chartmuseum)
@@ -94,4 +96,13 @@ func TestRemoteChartInstallUpgradeRollback(t *testing.T) {
 	// Finally, test rollback functionality. When rolling back, we should see the pods go back down to 1.
 	Rollback(t, options, releaseName, "")
 	waitForRemoteChartPods(t, kubectlOptions, releaseName, 1)
+
+	// The rollback is recorded as a new revision, with the values of the first one
+	release := GetReleaseStatus(t, options, releaseName)
+	assert.Equal(t, 3, release.Revision)
+	assert.Equal(t, "deployed", release.Info.Status)
+	history := GetReleaseHistory(t, options, releaseName)
+	require.Len(t, history, 3)
+	assert.Equal(t, "superseded", history[1].Status)
+	assert.Equal(t, "Rollback to 1", history[2].Description)
 }
@@ -0,0 +1,117 @@
+package helm
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// valuesSchemaFileName is the name of the JSON schema of the values of a chart.
+const valuesSchemaFileName = "values.schema.json"
+
+// ValidateValuesFiles validates each of the given values files against the values.schema.json of the chart in the
+// given folder, without a cluster or even the helm binary. This is useful to check that the example values files
+// documented with a chart conform to its schema. This will fail the test if any values file doesn't conform.
+func ValidateValuesFiles(t testing.TestingT, chartDir string, valuesFiles ...string) {
+	require.NoError(t, ValidateValuesFilesE(t, chartDir, valuesFiles...))
+}
+
+// ValidateValuesFilesE validates each of the given values files against the values.schema.json of the chart in the
+// given folder, without a cluster or even the helm binary. Same as helm, each values file is merged over the default
+// values.yaml of the chart before the validation, so that values files that only override some values are valid.
+// Returns a ValuesSchemaValidationError listing the problems of each values file that doesn't conform. The schemas of
+// the subcharts are not checked.
+func ValidateValuesFilesE(t testing.TestingT, chartDir string, valuesFiles ...string) error {
+	schemaPath := filepath.Join(chartDir, valuesSchemaFileName)
+	if !files.FileExists(schemaPath) {
+		return errors.WithStackTrace(ValuesSchemaNotFoundError{Path: schemaPath})
+	}
+	schemaBytes, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaBytes))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	defaults := map[string]interface{}{}
+	defaultsPath := filepath.Join(chartDir, "values.yaml")
+	if files.FileExists(defaultsPath) {
+		if defaults, err = readValuesFile(defaultsPath); err != nil {
+			return err
+		}
+	}
+
+	failures := map[string][]string{}
+	for _, valuesFile := range valuesFiles {
+		logger.Logf(t, "Validating values file %s against %s", valuesFile, schemaPath)
+		values, err := readValuesFile(valuesFile)
+		if err != nil {
+			return err
+		}
+
+		result, err := schema.Validate(gojsonschema.NewGoLoader(mergeValues(defaults, values)))
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+		for _, problem := range result.Errors() {
+			failures[valuesFile] = append(failures[valuesFile], problem.String())
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.WithStackTrace(ValuesSchemaValidationError{SchemaPath: schemaPath, Failures: failures})
+	}
+	return nil
+}
+
+// readValuesFile reads the values in the given YAML file. An empty file has no values.
+func readValuesFile(path string) (map[string]interface{}, error) {
+	if !files.FileExists(path) {
+		return nil, errors.WithStackTrace(ValuesFileNotFoundError{Path: path})
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return values, nil
+}
+
+// mergeValues returns the given values merged over the given defaults, the way helm does: the maps are merged
+// recursively, any other value replaces the default, and null removes the default.
+func mergeValues(defaults map[string]interface{}, values map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for key, value := range defaults {
+		out[key] = value
+	}
+	for key, value := range values {
+		if value == nil {
+			delete(out, key)
+			continue
+		}
+		valueMap, isMap := value.(map[string]interface{})
+		defaultMap, isDefaultMap := out[key].(map[string]interface{})
+		if isMap && isDefaultMap {
+			out[key] = mergeValues(defaultMap, valueMap)
+			continue
+		}
+		out[key] = value
+	}
+	return out
+}
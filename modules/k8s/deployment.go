@@ -0,0 +1,131 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// ListDeployments will look for deployments in the given namespace that match the given filters and return them. This
+// will fail the test if there is an error.
+func ListDeployments(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []appsv1.Deployment {
+	deployments, err := ListDeploymentsE(t, options, filters)
+	require.NoError(t, err)
+	return deployments
+}
+
+// ListDeploymentsE will look for deployments in the given namespace that match the given filters and return them.
+func ListDeploymentsE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) ([]appsv1.Deployment, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := clientset.AppsV1().Deployments(options.Namespace).List(context.Background(), filters)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// GetDeployment returns a Kubernetes deployment resource in the provided namespace with the given name. This will
+// fail the test if there is an error.
+func GetDeployment(t testing.TestingT, options *KubectlOptions, deploymentName string) *appsv1.Deployment {
+	deployment, err := GetDeploymentE(t, options, deploymentName)
+	require.NoError(t, err)
+	return deployment
+}
+
+// GetDeploymentE returns a Kubernetes deployment resource in the provided namespace with the given name.
+func GetDeploymentE(t testing.TestingT, options *KubectlOptions, deploymentName string) (*appsv1.Deployment, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.AppsV1().Deployments(options.Namespace).Get(context.Background(), deploymentName, metav1.GetOptions{})
+}
+
+// WaitUntilDeploymentAvailable waits until the rollout of the deployment is complete and all of its replicas are
+// available, retrying the check for the specified amount of times, sleeping for the provided duration between each
+// try. This will fail the test if there is an error, if the rollout is stuck or if the check times out.
+func WaitUntilDeploymentAvailable(t testing.TestingT, options *KubectlOptions, deploymentName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilDeploymentAvailableE(t, options, deploymentName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilDeploymentAvailableE waits until the rollout of the deployment is complete and all of its replicas are
+// available, retrying the check for the specified amount of times, sleeping for the provided duration between each
+// try. This stops waiting as soon as the rollout exceeds its progress deadline, returning a DeploymentRolloutStuck
+// error with the conditions of the deployment.
+func WaitUntilDeploymentAvailableE(t testing.TestingT, options *KubectlOptions, deploymentName string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for deployment %s to be provisioned.", deploymentName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			deployment, err := GetDeploymentE(t, options, deploymentName)
+			if err != nil {
+				return "", err
+			}
+			if IsDeploymentRolloutStuck(deployment) {
+				return "", retry.FatalError{Underlying: NewDeploymentRolloutStuckError(deployment)}
+			}
+			if !IsDeploymentAvailable(deployment) {
+				return "", NewDeploymentNotAvailableError(deployment)
+			}
+			return "Deployment is now available", nil
+		},
+	)
+	if err != nil {
+		logger.Logf(t, "Timedout waiting for Deployment to be provisioned: %s", err)
+		// The stuck rollouts stop the retries with a FatalError, which is unwrapped to return the DeploymentRolloutStuck
+		if fatalErr, isFatalErr := err.(retry.FatalError); isFatalErr {
+			return fatalErr.Underlying
+		}
+		return err
+	}
+	logger.Logf(t, message)
+	return nil
+}
+
+// IsDeploymentAvailable returns true if the rollout of the latest spec of the deployment is complete, the same way as
+// `kubectl rollout status`: the controller observed the latest generation, all the replicas are updated and
+// available, and there are no replicas of the previous revisions left.
+func IsDeploymentAvailable(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+	desiredReplicas := desiredReplicas(deployment.Spec.Replicas)
+	return deployment.Status.UpdatedReplicas >= desiredReplicas &&
+		deployment.Status.Replicas <= deployment.Status.UpdatedReplicas &&
+		deployment.Status.ReadyReplicas >= desiredReplicas &&
+		deployment.Status.AvailableReplicas >= desiredReplicas
+}
+
+// IsDeploymentRolloutStuck returns true if the rollout of the deployment exceeded its progress deadline, which means
+// that it is unlikely to complete without changes, e.g. because the pods keep crashing or the image can't be pulled.
+func IsDeploymentRolloutStuck(deployment *appsv1.Deployment) bool {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// desiredReplicas returns the given number of replicas of a spec, which defaults to 1.
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -2,7 +2,9 @@ package k8s
 
 import (
 	"fmt"
+	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -218,3 +220,120 @@ type JSONPathMalformedJSONPathResultErr struct {
 func (err JSONPathMalformedJSONPathResultErr) Error() string {
 	return fmt.Sprintf("Error unmarshaling json path output: %s", err.underlyingErr)
 }
+
+// UnsupportedSchemaVersionError is returned when there are no bundled schemas for the given Kubernetes version.
+type UnsupportedSchemaVersionError struct {
+	Version   string
+	Supported []string
+}
+
+func (err UnsupportedSchemaVersionError) Error() string {
+	return fmt.Sprintf("No schemas for Kubernetes %s. Supported versions are: %s", err.Version, strings.Join(err.Supported, ", "))
+}
+
+// ManifestValidationError is returned when Kubernetes manifests don't conform to the schemas.
+type ManifestValidationError struct {
+	KubeVersion string
+	Problems    []ManifestProblem
+}
+
+func (err ManifestValidationError) Error() string {
+	lines := []string{fmt.Sprintf("Found %d problem(s) validating the manifests against the schemas of Kubernetes %s:", len(err.Problems), err.KubeVersion)}
+	for _, problem := range err.Problems {
+		lines = append(lines, "  "+problem.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// KustomizationNotFoundError is returned when the folder of a kustomization doesn't exist.
+type KustomizationNotFoundError struct {
+	Path string
+}
+
+func (err KustomizationNotFoundError) Error() string {
+	return fmt.Sprintf("Kustomization %s not found", err.Path)
+}
+
+// DeploymentNotAvailable is returned when the rollout of a Kubernetes deployment is not yet complete.
+type DeploymentNotAvailable struct {
+	deployment *appsv1.Deployment
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err DeploymentNotAvailable) Error() string {
+	status := err.deployment.Status
+	return fmt.Sprintf(
+		"Deployment %s is not available: %d of %d replicas updated, %d ready, %d available, %d in total (observed generation %d of %d)%s",
+		err.deployment.Name,
+		status.UpdatedReplicas,
+		desiredReplicas(err.deployment.Spec.Replicas),
+		status.ReadyReplicas,
+		status.AvailableReplicas,
+		status.Replicas,
+		status.ObservedGeneration,
+		err.deployment.Generation,
+		formatDeploymentConditions(status.Conditions),
+	)
+}
+
+// NewDeploymentNotAvailableError returns a DeploymentNotAvailable struct when the rollout of a deployment is not
+// complete
+func NewDeploymentNotAvailableError(deployment *appsv1.Deployment) DeploymentNotAvailable {
+	return DeploymentNotAvailable{deployment}
+}
+
+// DeploymentRolloutStuck is returned when the rollout of a Kubernetes deployment exceeded its progress deadline.
+type DeploymentRolloutStuck struct {
+	deployment *appsv1.Deployment
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err DeploymentRolloutStuck) Error() string {
+	return fmt.Sprintf("Rollout of Deployment %s is stuck%s", err.deployment.Name, formatDeploymentConditions(err.deployment.Status.Conditions))
+}
+
+// NewDeploymentRolloutStuckError returns a DeploymentRolloutStuck struct when the rollout of a deployment exceeded its
+// progress deadline
+func NewDeploymentRolloutStuckError(deployment *appsv1.Deployment) DeploymentRolloutStuck {
+	return DeploymentRolloutStuck{deployment}
+}
+
+// formatDeploymentConditions formats the given deployment conditions for the error messages.
+func formatDeploymentConditions(conditions []appsv1.DeploymentCondition) string {
+	out := ""
+	for _, condition := range conditions {
+		out += fmt.Sprintf("\n  %s=%s (%s): %s", condition.Type, condition.Status, condition.Reason, condition.Message)
+	}
+	return out
+}
+
+// StatefulSetNotAvailable is returned when the rollout of a Kubernetes statefulset is not yet complete.
+type StatefulSetNotAvailable struct {
+	statefulSet *appsv1.StatefulSet
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err StatefulSetNotAvailable) Error() string {
+	status := err.statefulSet.Status
+	out := fmt.Sprintf(
+		"StatefulSet %s is not available: %d of %d replicas ready, %d updated to revision %s, current revision %s (observed generation %d of %d)",
+		err.statefulSet.Name,
+		status.ReadyReplicas,
+		desiredReplicas(err.statefulSet.Spec.Replicas),
+		status.UpdatedReplicas,
+		status.UpdateRevision,
+		status.CurrentRevision,
+		status.ObservedGeneration,
+		err.statefulSet.Generation,
+	)
+	for _, condition := range status.Conditions {
+		out += fmt.Sprintf("\n  %s=%s (%s): %s", condition.Type, condition.Status, condition.Reason, condition.Message)
+	}
+	return out
+}
+
+// NewStatefulSetNotAvailableError returns a StatefulSetNotAvailable struct when the rollout of a statefulset is not
+// complete
+func NewStatefulSetNotAvailableError(statefulSet *appsv1.StatefulSet) StatefulSetNotAvailable {
+	return StatefulSetNotAvailable{statefulSet}
+}
@@ -25,6 +25,17 @@ func RunKubectlE(t testing.TestingT, options *KubectlOptions, args ...string) er
 // RunKubectlAndGetOutputE will call kubectl using the provided options and args, returning the output of stdout and
 // stderr.
 func RunKubectlAndGetOutputE(t testing.TestingT, options *KubectlOptions, args ...string) (string, error) {
+	return shell.RunCommandAndGetOutputE(t, kubectlCommand(options, args...))
+}
+
+// runKubectlAndGetStdOutE will call kubectl using the provided options and args, returning only the output of stdout,
+// e.g. for the commands that output manifests and may write warnings to stderr.
+func runKubectlAndGetStdOutE(t testing.TestingT, options *KubectlOptions, args ...string) (string, error) {
+	return shell.RunCommandAndGetStdOutE(t, kubectlCommand(options, args...))
+}
+
+// kubectlCommand returns the kubectl command for the provided options and args.
+func kubectlCommand(options *KubectlOptions, args ...string) shell.Command {
 	cmdArgs := []string{}
 	if options.ContextName != "" {
 		cmdArgs = append(cmdArgs, "--context", options.ContextName)
@@ -36,12 +47,11 @@ func RunKubectlAndGetOutputE(t testing.TestingT, options *KubectlOptions, args .
 		cmdArgs = append(cmdArgs, "--namespace", options.Namespace)
 	}
 	cmdArgs = append(cmdArgs, args...)
-	command := shell.Command{
+	return shell.Command{
 		Command: "kubectl",
 		Args:    cmdArgs,
 		Env:     options.Env,
 	}
-	return shell.RunCommandAndGetOutputE(t, command)
 }
 
 // KubectlDelete will take in a file path and delete it from the cluster targeted by KubectlOptions. If there are any
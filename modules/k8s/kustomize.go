@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"encoding/json"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// RenderKustomize runs `kubectl kustomize` to render the kustomization in the given folder (e.g., an overlay), and
+// returns the rendered manifests as a multi-document YAML string. This doesn't need a cluster, so it can be used to
+// unit test the overlays. This will fail the test if there is an error.
+func RenderKustomize(t testing.TestingT, options *KubectlOptions, kustomizationDir string) string {
+	out, err := RenderKustomizeE(t, options, kustomizationDir)
+	require.NoError(t, err)
+	return out
+}
+
+// RenderKustomizeE runs `kubectl kustomize` to render the kustomization in the given folder (e.g., an overlay), and
+// returns the rendered manifests as a multi-document YAML string. This doesn't need a cluster, so it can be used to
+// unit test the overlays.
+func RenderKustomizeE(t testing.TestingT, options *KubectlOptions, kustomizationDir string) (string, error) {
+	if !files.FileExists(kustomizationDir) {
+		return "", errors.WithStackTrace(KustomizationNotFoundError{Path: kustomizationDir})
+	}
+	return runKubectlAndGetStdOutE(t, options, "kustomize", kustomizationDir)
+}
+
+// RenderKustomizeAsObjects runs `kubectl kustomize` to render the kustomization in the given folder, same as
+// RenderKustomize, and decodes the rendered manifests into typed objects, in the order they were rendered. The objects
+// of the kinds known to client-go are decoded into their types (e.g., *appsv1.Deployment), and the others (e.g.,
+// custom resources) into *unstructured.Unstructured. For example:
+//
+// objects := k8s.RenderKustomizeAsObjects(t, options, "../overlays/production")
+// deployment := objects[0].(*appsv1.Deployment)
+//
+// This will fail the test if there is an error rendering or decoding the manifests.
+func RenderKustomizeAsObjects(t testing.TestingT, options *KubectlOptions, kustomizationDir string) []runtime.Object {
+	objects, err := RenderKustomizeAsObjectsE(t, options, kustomizationDir)
+	require.NoError(t, err)
+	return objects
+}
+
+// RenderKustomizeAsObjectsE runs `kubectl kustomize` to render the kustomization in the given folder, same as
+// RenderKustomizeE, and decodes the rendered manifests into typed objects, in the order they were rendered. The
+// objects of the kinds known to client-go are decoded into their types (e.g., *appsv1.Deployment), and the others
+// (e.g., custom resources) into *unstructured.Unstructured.
+func RenderKustomizeAsObjectsE(t testing.TestingT, options *KubectlOptions, kustomizationDir string) ([]runtime.Object, error) {
+	out, err := RenderKustomizeE(t, options, kustomizationDir)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeManifestsE(out)
+}
+
+// DecodeManifests decodes all the objects in the given multi-document YAML into typed objects, same as
+// DecodeManifestsE. This will fail the test if there is an error.
+func DecodeManifests(t testing.TestingT, yamlData string) []runtime.Object {
+	objects, err := DecodeManifestsE(yamlData)
+	require.NoError(t, err)
+	return objects
+}
+
+// DecodeManifestsE decodes all the objects in the given multi-document YAML into typed objects, in order. The objects
+// of the kinds known to client-go are decoded into their types (e.g., *appsv1.Deployment), and the others (e.g.,
+// custom resources) into *unstructured.Unstructured. Empty documents are ignored, and the items of List objects are
+// decoded as separate objects.
+func DecodeManifestsE(yamlData string) ([]runtime.Object, error) {
+	manifests, err := decodeManifests(yamlData)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := scheme.Codecs.UniversalDeserializer()
+	objects := []runtime.Object{}
+	for _, manifest := range manifests {
+		jsonData, err := json.Marshal(manifest)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		object, _, err := decoder.Decode(jsonData, nil, nil)
+		if runtime.IsNotRegisteredError(err) {
+			object, err = &unstructured.Unstructured{Object: manifest}, nil
+		}
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// KubectlApplyKustomize runs `kubectl apply -k` to apply the kustomization in the given folder to the cluster targeted
+// by KubectlOptions. This will fail the test if there is an error.
+func KubectlApplyKustomize(t testing.TestingT, options *KubectlOptions, kustomizationDir string) {
+	require.NoError(t, KubectlApplyKustomizeE(t, options, kustomizationDir))
+}
+
+// KubectlApplyKustomizeE runs `kubectl apply -k` to apply the kustomization in the given folder to the cluster
+// targeted by KubectlOptions.
+func KubectlApplyKustomizeE(t testing.TestingT, options *KubectlOptions, kustomizationDir string) error {
+	if !files.FileExists(kustomizationDir) {
+		return errors.WithStackTrace(KustomizationNotFoundError{Path: kustomizationDir})
+	}
+	return RunKubectlE(t, options, "apply", "-k", kustomizationDir)
+}
+
+// KubectlDeleteKustomize runs `kubectl delete -k` to delete the resources of the kustomization in the given folder
+// from the cluster targeted by KubectlOptions. This will fail the test if there is an error.
+func KubectlDeleteKustomize(t testing.TestingT, options *KubectlOptions, kustomizationDir string) {
+	require.NoError(t, KubectlDeleteKustomizeE(t, options, kustomizationDir))
+}
+
+// KubectlDeleteKustomizeE runs `kubectl delete -k` to delete the resources of the kustomization in the given folder
+// from the cluster targeted by KubectlOptions.
+func KubectlDeleteKustomizeE(t testing.TestingT, options *KubectlOptions, kustomizationDir string) error {
+	if !files.FileExists(kustomizationDir) {
+		return errors.WithStackTrace(KustomizationNotFoundError{Path: kustomizationDir})
+	}
+	return RunKubectlE(t, options, "delete", "-k", kustomizationDir)
+}
@@ -0,0 +1,70 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+)
+
+func TestKubectlApplyKustomize(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+
+	kustomizationDir := t.TempDir()
+	writeKustomizationFile(t, kustomizationDir, "kustomization.yaml", fmt.Sprintf(EXAMPLE_KUSTOMIZATION_YAML_TEMPLATE, uniqueID))
+	writeKustomizationFile(t, kustomizationDir, "namespace.yaml", fmt.Sprintf(EXAMPLE_KUSTOMIZATION_NAMESPACE_YAML_TEMPLATE, uniqueID))
+	writeKustomizationFile(t, kustomizationDir, "service.yaml", EXAMPLE_KUSTOMIZATION_SERVICE_YAML)
+
+	KubectlApplyKustomize(t, options, kustomizationDir)
+	defer KubectlDeleteKustomize(t, options, kustomizationDir)
+
+	service := GetService(t, options, "nginx")
+	require.Equal(t, uniqueID, service.Namespace)
+	require.Equal(t, "test", service.Labels["env"])
+}
+
+func writeKustomizationFile(t *testing.T, dir string, name string, contents string) {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+}
+
+const EXAMPLE_KUSTOMIZATION_YAML_TEMPLATE = `namespace: %s
+commonLabels:
+  env: test
+resources:
+  - namespace.yaml
+  - service.yaml
+`
+
+const EXAMPLE_KUSTOMIZATION_NAMESPACE_YAML_TEMPLATE = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s
+`
+
+const EXAMPLE_KUSTOMIZATION_SERVICE_YAML = `apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  selector:
+    app: nginx
+  ports:
+    - port: 80
+`
@@ -0,0 +1,73 @@
+package k8s
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const renderedKustomization = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-nginx
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - image: nginx:1.21
+        name: nginx
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: prod-widget
+spec:
+  size: 2
+`
+
+// NOTE: this test is not parallel, because it puts a fake kubectl on the PATH.
+func TestRenderKustomizeAsObjects(t *testing.T) {
+	binDir := t.TempDir()
+	renderedPath := filepath.Join(binDir, "rendered.yaml")
+	require.NoError(t, ioutil.WriteFile(renderedPath, []byte(renderedKustomization), 0644))
+	script := "#!/bin/sh\n[ \"$1\" = kustomize ] || exit 1\necho 'Warning: a warning on stderr' >&2\ncat " + renderedPath + "\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "kubectl"), []byte(script), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	options := NewKubectlOptions("", "", "")
+	kustomizationDir := t.TempDir()
+	assert.Equal(t, renderedKustomization, RenderKustomize(t, options, kustomizationDir)+"\n")
+
+	objects := RenderKustomizeAsObjects(t, options, kustomizationDir)
+	require.Len(t, objects, 2)
+	deployment, ok := objects[0].(*appsv1.Deployment)
+	require.True(t, ok)
+	assert.Equal(t, "prod-nginx", deployment.Name)
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+	widget, ok := objects[1].(*unstructured.Unstructured)
+	require.True(t, ok)
+	assert.Equal(t, "prod-widget", widget.GetName())
+}
+
+func TestRenderKustomizeEMissingDir(t *testing.T) {
+	t.Parallel()
+
+	_, err := RenderKustomizeE(t, NewKubectlOptions("", "", ""), filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+	_, ok := errors.Unwrap(err).(KustomizationNotFoundError)
+	assert.True(t, ok)
+}
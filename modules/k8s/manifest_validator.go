@@ -0,0 +1,541 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/gruntwork-io/go-commons/errors"
+	goversion "github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// bundledSchemas are the OpenAPI schemas of the Kubernetes releases, generated with schemas/generate.go.
+//
+//go:embed schemas/*.json.gz
+var bundledSchemas embed.FS
+
+const (
+	definitionRefPrefix = "#/definitions/"
+	objectMetaRef       = definitionRefPrefix + "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+	quantityDefinition  = "io.k8s.apimachinery.pkg.api.resource.Quantity"
+)
+
+// manifestKind identifies the schema of an object by its apiVersion (e.g., apps/v1) and kind (e.g., Deployment).
+type manifestKind struct {
+	APIVersion string
+	Kind       string
+}
+
+// ManifestProblem is a problem found in a Kubernetes manifest by a ManifestValidator.
+type ManifestProblem struct {
+	Source    string // The file the object was read from, if any
+	Kind      string
+	Namespace string
+	Name      string
+	Path      string // The path of the offending field in the object, e.g., .spec.template.spec.containers[0].image
+	Message   string
+}
+
+// String returns the problem as a human readable string.
+func (problem ManifestProblem) String() string {
+	object := problem.Kind + " "
+	if problem.Namespace != "" {
+		object += problem.Namespace + "/"
+	}
+	object += problem.Name
+	if problem.Source != "" {
+		object = fmt.Sprintf("%s (%s)", object, problem.Source)
+	}
+	return fmt.Sprintf("%s: %s: %s", object, problem.Path, problem.Message)
+}
+
+// ManifestValidator validates Kubernetes manifests against the OpenAPI schemas of a Kubernetes release, which are
+// bundled with this module, and the schemas of the given CRDs, without the need of a cluster. Use
+// NewManifestValidator to construct one.
+type ManifestValidator struct {
+	// KubeVersion is the Kubernetes release of the schemas, e.g., 1.22
+	KubeVersion string
+
+	definitions map[string]map[string]interface{}
+	kinds       map[manifestKind]map[string]interface{}
+}
+
+// SupportedSchemaVersions returns the Kubernetes releases (e.g., 1.22) whose schemas are bundled with this module,
+// sorted. This will fail the test if there is an error.
+func SupportedSchemaVersions(t testing.TestingT) []string {
+	versions, err := SupportedSchemaVersionsE(t)
+	require.NoError(t, err)
+	return versions
+}
+
+// SupportedSchemaVersionsE returns the Kubernetes releases (e.g., 1.22) whose schemas are bundled with this module,
+// sorted.
+func SupportedSchemaVersionsE(t testing.TestingT) ([]string, error) {
+	entries, err := bundledSchemas.ReadDir("schemas")
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	versions := []*goversion.Version{}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(strings.TrimPrefix(entry.Name(), "kubernetes-"), ".json.gz")
+		if version, err := goversion.NewVersion(name); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	sort.Sort(goversion.Collection(versions))
+
+	out := []string{}
+	for _, version := range versions {
+		out = append(out, version.Original())
+	}
+	return out, nil
+}
+
+// NewManifestValidator returns a ManifestValidator for the given Kubernetes version (e.g., 1.22 or v1.22.3), which
+// also knows the custom resources defined by the CustomResourceDefinitions in the given files. This will fail the test
+// if the version is not supported (see SupportedSchemaVersions) or the CRD files can't be read.
+func NewManifestValidator(t testing.TestingT, kubeVersion string, crdFiles ...string) *ManifestValidator {
+	validator, err := NewManifestValidatorE(t, kubeVersion, crdFiles...)
+	require.NoError(t, err)
+	return validator
+}
+
+// NewManifestValidatorE returns a ManifestValidator for the given Kubernetes version (e.g., 1.22 or v1.22.3), which
+// also knows the custom resources defined by the CustomResourceDefinitions in the given files. Only the major and
+// minor versions matter. The documents of the CRD files that are not CustomResourceDefinitions are ignored, so that
+// e.g. the crds folder of a chart can be used as is.
+func NewManifestValidatorE(t testing.TestingT, kubeVersion string, crdFiles ...string) (*ManifestValidator, error) {
+	version, err := goversion.NewVersion(kubeVersion)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	segments := version.Segments()
+	release := fmt.Sprintf("%d.%d", segments[0], segments[1])
+
+	file, err := bundledSchemas.Open(path.Join("schemas", fmt.Sprintf("kubernetes-%s.json.gz", release)))
+	if err != nil {
+		supported, supportedErr := SupportedSchemaVersionsE(t)
+		if supportedErr != nil {
+			return nil, supportedErr
+		}
+		return nil, errors.WithStackTrace(UnsupportedSchemaVersionError{Version: kubeVersion, Supported: supported})
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	var spec struct {
+		Definitions map[string]map[string]interface{} `json:"definitions"`
+	}
+	if err := json.NewDecoder(reader).Decode(&spec); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	validator := &ManifestValidator{
+		KubeVersion: release,
+		definitions: spec.Definitions,
+		kinds:       map[manifestKind]map[string]interface{}{},
+	}
+
+	// Index the definitions by apiVersion and kind. Go through them sorted, so that the index doesn't depend on the
+	// order of the map.
+	names := []string{}
+	for name := range spec.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		definition := spec.Definitions[name]
+		gvks, _ := definition["x-kubernetes-group-version-kind"].([]interface{})
+		for _, gvk := range gvks {
+			gvkMap, _ := gvk.(map[string]interface{})
+			group, _ := gvkMap["group"].(string)
+			kind := manifestKind{APIVersion: apiVersion(group, toString(gvkMap["version"])), Kind: toString(gvkMap["kind"])}
+			if _, exists := validator.kinds[kind]; !exists {
+				validator.kinds[kind] = definition
+			}
+		}
+	}
+
+	for _, crdFile := range crdFiles {
+		if err := validator.addCRDFile(t, crdFile); err != nil {
+			return nil, err
+		}
+	}
+	return validator, nil
+}
+
+// addCRDFile adds the schemas of the custom resources defined by the CustomResourceDefinitions in the given file.
+func (validator *ManifestValidator) addCRDFile(t testing.TestingT, crdFile string) error {
+	logger.Logf(t, "Loading the CustomResourceDefinitions in %s", crdFile)
+	data, err := ioutil.ReadFile(crdFile)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	objects, err := decodeManifests(string(data))
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if toString(object["kind"]) != "CustomResourceDefinition" {
+			continue
+		}
+		spec, _ := object["spec"].(map[string]interface{})
+		names, _ := spec["names"].(map[string]interface{})
+		group := toString(spec["group"])
+		kind := toString(names["kind"])
+
+		// apiextensions.k8s.io/v1beta1 CRDs may have a schema for all the versions in spec.validation
+		commonSchema := crdSchema(spec["validation"])
+		versions, _ := spec["versions"].([]interface{})
+		if len(versions) == 0 && spec["version"] != nil {
+			versions = []interface{}{map[string]interface{}{"name": spec["version"]}}
+		}
+		for _, version := range versions {
+			versionMap, _ := version.(map[string]interface{})
+			schema := crdSchema(versionMap["schema"])
+			if schema == nil {
+				schema = commonSchema
+			}
+			if schema == nil {
+				// Without a schema, any field is allowed
+				schema = map[string]interface{}{"x-kubernetes-preserve-unknown-fields": true}
+			}
+			validator.kinds[manifestKind{APIVersion: apiVersion(group, toString(versionMap["name"])), Kind: kind}] = schema
+		}
+	}
+	return nil
+}
+
+// crdSchema returns the openAPIV3Schema in the given CRD validation, if any.
+func crdSchema(validation interface{}) map[string]interface{} {
+	validationMap, _ := validation.(map[string]interface{})
+	schema, _ := validationMap["openAPIV3Schema"].(map[string]interface{})
+	return schema
+}
+
+// ValidateManifests validates all the objects in the given multi-document YAML (e.g., the output of
+// helm.RenderTemplate) against the schemas. This will fail the test if any object has an unknown field, a field of the
+// wrong type, misses a required field, or is of a kind without a schema.
+func (validator *ManifestValidator) ValidateManifests(t testing.TestingT, yamlData string) {
+	require.NoError(t, validator.ValidateManifestsE(t, yamlData))
+}
+
+// ValidateManifestsE validates all the objects in the given multi-document YAML (e.g., the output of
+// helm.RenderTemplate) against the schemas. Returns a ManifestValidationError listing the problems if any object has an
+// unknown field, a field of the wrong type, misses a required field, or is of a kind without a schema.
+func (validator *ManifestValidator) ValidateManifestsE(t testing.TestingT, yamlData string) error {
+	logger.Logf(t, "Validating manifests against the schemas of Kubernetes %s", validator.KubeVersion)
+	problems, err := validator.validateManifests("", yamlData)
+	if err != nil {
+		return err
+	}
+	return validator.toError(problems)
+}
+
+// ValidateManifestFiles validates all the objects in the given manifest files (e.g., the ones passed to KubectlApply)
+// against the schemas. This will fail the test if any object has an unknown field, a field of the wrong type, misses a
+// required field, or is of a kind without a schema.
+func (validator *ManifestValidator) ValidateManifestFiles(t testing.TestingT, paths ...string) {
+	require.NoError(t, validator.ValidateManifestFilesE(t, paths...))
+}
+
+// ValidateManifestFilesE validates all the objects in the given manifest files (e.g., the ones passed to KubectlApply)
+// against the schemas. Returns a ManifestValidationError listing the problems of all the files if any object has an
+// unknown field, a field of the wrong type, misses a required field, or is of a kind without a schema.
+func (validator *ManifestValidator) ValidateManifestFilesE(t testing.TestingT, paths ...string) error {
+	problems := []ManifestProblem{}
+	for _, manifestPath := range paths {
+		logger.Logf(t, "Validating manifest file %s against the schemas of Kubernetes %s", manifestPath, validator.KubeVersion)
+		data, err := ioutil.ReadFile(manifestPath)
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+		fileProblems, err := validator.validateManifests(manifestPath, string(data))
+		if err != nil {
+			return err
+		}
+		problems = append(problems, fileProblems...)
+	}
+	return validator.toError(problems)
+}
+
+// toError returns a ManifestValidationError with the given problems, if any.
+func (validator *ManifestValidator) toError(problems []ManifestProblem) error {
+	if len(problems) > 0 {
+		return errors.WithStackTrace(ManifestValidationError{KubeVersion: validator.KubeVersion, Problems: problems})
+	}
+	return nil
+}
+
+// validateManifests returns the problems of all the objects in the given multi-document YAML read from the given
+// source.
+func (validator *ManifestValidator) validateManifests(source string, yamlData string) ([]ManifestProblem, error) {
+	objects, err := decodeManifests(yamlData)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []ManifestProblem{}
+	for _, object := range objects {
+		metadata, _ := object["metadata"].(map[string]interface{})
+		newProblem := func(path string, message string) {
+			problems = append(problems, ManifestProblem{
+				Source:    source,
+				Kind:      toString(object["kind"]),
+				Namespace: toString(metadata["namespace"]),
+				Name:      toString(metadata["name"]),
+				Path:      path,
+				Message:   message,
+			})
+		}
+
+		kind := manifestKind{APIVersion: toString(object["apiVersion"]), Kind: toString(object["kind"])}
+		schema, ok := validator.kinds[kind]
+		if !ok {
+			newProblem(".", fmt.Sprintf("no schema for kind %s of apiVersion %s in Kubernetes %s", kind.Kind, kind.APIVersion, validator.KubeVersion))
+			continue
+		}
+		validator.validateValue(withObjectFields(schema), object, "", newProblem)
+	}
+	return problems, nil
+}
+
+// withObjectFields returns the given root schema of a kind with the apiVersion, kind and metadata fields that are
+// common to all the objects, which the schemas of the CRDs often leave out.
+func withObjectFields(schema map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{
+		"apiVersion": map[string]interface{}{"type": "string"},
+		"kind":       map[string]interface{}{"type": "string"},
+		"metadata":   map[string]interface{}{"$ref": objectMetaRef},
+	}
+	schemaProperties, _ := schema["properties"].(map[string]interface{})
+	for name, property := range schemaProperties {
+		properties[name] = property
+	}
+
+	out := map[string]interface{}{}
+	for field, value := range schema {
+		out[field] = value
+	}
+	out["properties"] = properties
+	if out["type"] == nil {
+		out["type"] = "object"
+	}
+	return out
+}
+
+// validateValue validates the given value against the given schema, calling newProblem with the path of each
+// offending field.
+func (validator *ManifestValidator) validateValue(schema map[string]interface{}, value interface{}, path string, newProblem func(path string, message string)) {
+	// Null fields are dropped by the API server, so they are valid whatever their type
+	if value == nil {
+		return
+	}
+
+	for schema["$ref"] != nil {
+		name := strings.TrimPrefix(toString(schema["$ref"]), definitionRefPrefix)
+		if name == quantityDefinition {
+			// Quantities are strings, but numbers such as `cpu: 1` are accepted too
+			switch value.(type) {
+			case string, float64:
+			default:
+				newProblem(pathOrRoot(path), fmt.Sprintf("expected a quantity, got %s", jsonType(value)))
+			}
+			return
+		}
+		definition, ok := validator.definitions[name]
+		if !ok {
+			return
+		}
+		schema = definition
+	}
+
+	if schema["format"] == "int-or-string" || schema["x-kubernetes-int-or-string"] == true {
+		if _, isString := value.(string); !isString && !isInteger(value) {
+			newProblem(pathOrRoot(path), fmt.Sprintf("expected an integer or a string, got %s", jsonType(value)))
+		}
+		return
+	}
+
+	schemaType := toString(schema["type"])
+	if schemaType == "" && schema["properties"] != nil {
+		schemaType = "object"
+	}
+	switch schemaType {
+	case "object":
+		validator.validateObject(schema, value, path, newProblem)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			newProblem(pathOrRoot(path), fmt.Sprintf("expected an array, got %s", jsonType(value)))
+			return
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		if itemSchema == nil {
+			return
+		}
+		for i, item := range items {
+			validator.validateValue(itemSchema, item, fmt.Sprintf("%s[%d]", path, i), newProblem)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			newProblem(pathOrRoot(path), fmt.Sprintf("expected a string, got %s", jsonType(value)))
+		}
+	case "integer":
+		if !isInteger(value) {
+			newProblem(pathOrRoot(path), fmt.Sprintf("expected an integer, got %s", jsonType(value)))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			newProblem(pathOrRoot(path), fmt.Sprintf("expected a number, got %s", jsonType(value)))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			newProblem(pathOrRoot(path), fmt.Sprintf("expected a boolean, got %s", jsonType(value)))
+		}
+	}
+	// A schema without a type allows any value
+}
+
+// validateObject validates the fields of the given value against the given object schema.
+func (validator *ManifestValidator) validateObject(schema map[string]interface{}, value interface{}, path string, newProblem func(path string, message string)) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		newProblem(pathOrRoot(path), fmt.Sprintf("expected an object, got %s", jsonType(value)))
+		return
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	additionalProperties := schema["additionalProperties"]
+	// An object schema without any properties, such as the one of RawExtension, allows any field
+	allowsUnknownFields := schema["x-kubernetes-preserve-unknown-fields"] == true ||
+		additionalProperties == true ||
+		(properties == nil && additionalProperties == nil)
+
+	fields := []string{}
+	for field := range object {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		fieldPath := path + "." + field
+		if property, ok := properties[field].(map[string]interface{}); ok {
+			validator.validateValue(property, object[field], fieldPath, newProblem)
+		} else if additionalSchema, ok := additionalProperties.(map[string]interface{}); ok {
+			validator.validateValue(additionalSchema, object[field], fieldPath, newProblem)
+		} else if !allowsUnknownFields {
+			newProblem(fieldPath, "unknown field")
+		}
+	}
+
+	required, _ := schema["required"].([]interface{})
+	for _, field := range required {
+		if _, ok := object[toString(field)]; !ok {
+			newProblem(path+"."+toString(field), "missing required field")
+		}
+	}
+}
+
+// decodeManifests decodes all the objects in the given multi-document YAML. Empty documents are ignored, and the items
+// of List objects are returned as separate objects.
+func decodeManifests(yamlData string) ([]map[string]interface{}, error) {
+	objects := []map[string]interface{}{}
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(strings.NewReader(yamlData)))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+
+		jsonData, err := yaml.YAMLToJSON(document)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		if len(bytes.TrimSpace(jsonData)) == 0 || string(bytes.TrimSpace(jsonData)) == "null" {
+			continue
+		}
+		object := map[string]interface{}{}
+		if err := json.Unmarshal(jsonData, &object); err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+
+		items, isList := object["items"].([]interface{})
+		if isList && strings.HasSuffix(toString(object["kind"]), "List") {
+			for _, item := range items {
+				if itemObject, ok := item.(map[string]interface{}); ok {
+					objects = append(objects, itemObject)
+				}
+			}
+			continue
+		}
+		objects = append(objects, object)
+	}
+}
+
+// apiVersion returns the apiVersion of the given API group and version, e.g., apps/v1, or v1 for the core group.
+func apiVersion(group string, version string) string {
+	if group == "" {
+		return version
+	}
+	return group + "/" + version
+}
+
+// pathOrRoot returns the given field path, or . for the root of the object.
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+// isInteger returns true if the given decoded JSON value is an integral number.
+func isInteger(value interface{}) bool {
+	number, ok := value.(float64)
+	return ok && number == math.Trunc(number)
+}
+
+// jsonType returns the JSON type of the given decoded JSON value, for the problem messages.
+func jsonType(value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", typed)
+	case float64:
+		return fmt.Sprintf("number %v", typed)
+	case bool:
+		return fmt.Sprintf("boolean %v", typed)
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// toString returns the given decoded JSON value if it is a string, or an empty string otherwise.
+func toString(value interface{}) string {
+	str, _ := value.(string)
+	return str
+}
@@ -0,0 +1,201 @@
+package k8s
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validManifests = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  labels:
+    app: nginx
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: nginx
+  strategy:
+    rollingUpdate:
+      maxSurge: 25%
+      maxUnavailable: 1
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+        - name: nginx
+          image: nginx:1.21
+          ports:
+            - containerPort: 80
+          resources:
+            limits:
+              cpu: 1
+              memory: 128Mi
+---
+# Disabled template
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: Service
+    metadata:
+      name: nginx
+    spec:
+      selector:
+        app: nginx
+      ports:
+        - port: 80
+          targetPort: http
+`
+
+const crdManifest = `---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              required: ["size"]
+              properties:
+                size:
+                  type: integer
+                extra:
+                  type: object
+                  x-kubernetes-preserve-unknown-fields: true
+`
+
+func TestSupportedSchemaVersions(t *testing.T) {
+	t.Parallel()
+
+	versions := SupportedSchemaVersions(t)
+	assert.Contains(t, versions, "1.22")
+	assert.Equal(t, "1.20", versions[0])
+}
+
+func TestNewManifestValidatorUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewManifestValidatorE(t, "1.10")
+	require.Error(t, err)
+	_, ok := errors.Unwrap(err).(UnsupportedSchemaVersionError)
+	assert.True(t, ok)
+}
+
+func TestValidateManifestsValid(t *testing.T) {
+	t.Parallel()
+
+	validator := NewManifestValidator(t, "v1.22.3")
+	assert.Equal(t, "1.22", validator.KubeVersion)
+	validator.ValidateManifests(t, validManifests)
+}
+
+func TestValidateManifestsInvalid(t *testing.T) {
+	t.Parallel()
+
+	validator := NewManifestValidator(t, "1.25")
+	err := validator.ValidateManifestsE(t, `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: web
+  annotations:
+    enabled: true
+spec:
+  replicas: "2"
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    spec:
+      containers:
+        - image: nginx:1.21
+          imagePullPolicy: Always
+          port: 80
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: nginx
+`)
+	require.Error(t, err)
+	validationErr, ok := errors.Unwrap(err).(ManifestValidationError)
+	require.True(t, ok)
+
+	messages := []string{}
+	for _, problem := range validationErr.Problems {
+		messages = append(messages, problem.Kind+" "+problem.Name+" "+problem.Path+": "+problem.Message)
+	}
+	assert.Equal(t, []string{
+		`Deployment nginx .metadata.annotations.enabled: expected a string, got boolean true`,
+		`Deployment nginx .spec.replicas: expected an integer, got string "2"`,
+		`Deployment nginx .spec.template.spec.containers[0].port: unknown field`,
+		`Deployment nginx .spec.template.spec.containers[0].name: missing required field`,
+		`Ingress nginx .: no schema for kind Ingress of apiVersion extensions/v1beta1 in Kubernetes 1.25`,
+	}, messages)
+	assert.Equal(t, "web", validationErr.Problems[0].Namespace)
+}
+
+func TestValidateManifestFilesWithCRD(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	crdFile := filepath.Join(dir, "crds.yaml")
+	require.NoError(t, ioutil.WriteFile(crdFile, []byte(crdManifest), 0644))
+	manifestFile := filepath.Join(dir, "widget.yaml")
+	require.NoError(t, ioutil.WriteFile(manifestFile, []byte(`---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: small
+spec:
+  size: 1
+  extra:
+    anything: goes
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: broken
+  labelz: {}
+spec:
+  size: 1.5
+  color: red
+`), 0644))
+
+	validator := NewManifestValidator(t, "1.29", crdFile)
+	err := validator.ValidateManifestFilesE(t, manifestFile)
+	require.Error(t, err)
+	validationErr, ok := errors.Unwrap(err).(ManifestValidationError)
+	require.True(t, ok)
+
+	assert.Equal(t, []ManifestProblem{
+		{Source: manifestFile, Kind: "Widget", Name: "broken", Path: ".metadata.labelz", Message: "unknown field"},
+		{Source: manifestFile, Kind: "Widget", Name: "broken", Path: ".spec.color", Message: "unknown field"},
+		{Source: manifestFile, Kind: "Widget", Name: "broken", Path: ".spec.size", Message: "expected an integer, got number 1.5"},
+	}, validationErr.Problems)
+}
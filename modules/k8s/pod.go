@@ -53,6 +53,28 @@ func GetPodE(t testing.TestingT, options *KubectlOptions, podName string) (*core
 	return clientset.CoreV1().Pods(options.Namespace).Get(context.Background(), podName, metav1.GetOptions{})
 }
 
+// GetPodLogs returns the logs of the given container of the pod with the given name, in the provided namespace. If the
+// pod has a single container, containerName can be empty. This will fail the test if there is an error.
+func GetPodLogs(t testing.TestingT, options *KubectlOptions, podName string, containerName string) string {
+	logs, err := GetPodLogsE(t, options, podName, containerName)
+	require.NoError(t, err)
+	return logs
+}
+
+// GetPodLogsE returns the logs of the given container of the pod with the given name, in the provided namespace. If
+// the pod has a single container, containerName can be empty.
+func GetPodLogsE(t testing.TestingT, options *KubectlOptions, podName string, containerName string) (string, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return "", err
+	}
+	logs, err := clientset.CoreV1().Pods(options.Namespace).GetLogs(podName, &corev1.PodLogOptions{Container: containerName}).DoRaw(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return string(logs), nil
+}
+
 // WaitUntilNumPodsCreated waits until the desired number of pods are created that match the provided filter. This will
 // retry the check for the specified amount of times, sleeping for the provided duration between each try. This will
 // fail the test if the retry times out.
@@ -0,0 +1,151 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsDeploymentAvailable(t *testing.T) {
+	t.Parallel()
+
+	replicas := int32(2)
+	cases := []struct {
+		title          string
+		status         appsv1.DeploymentStatus
+		expectedResult bool
+	}{
+		{
+			title:          "TestIsDeploymentAvailable",
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+			expectedResult: true,
+		},
+		{
+			title:          "TestIsDeploymentGenerationNotObserved",
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+			expectedResult: false,
+		},
+		{
+			title:          "TestIsDeploymentReplicasNotUpdated",
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, ReadyReplicas: 2, AvailableReplicas: 2},
+			expectedResult: false,
+		},
+		{
+			title:          "TestIsDeploymentOldReplicasLeft",
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, ReadyReplicas: 3, AvailableReplicas: 3},
+			expectedResult: false,
+		},
+		{
+			title:          "TestIsDeploymentReplicasNotReady",
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 1, AvailableReplicas: 1},
+			expectedResult: false,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     tc.status,
+			}
+			actualResult := IsDeploymentAvailable(deployment)
+			require.Equal(t, tc.expectedResult, actualResult)
+		})
+	}
+}
+
+func TestIsDeploymentRolloutStuck(t *testing.T) {
+	t.Parallel()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-deployment"},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Reason: "MinimumReplicasUnavailable", Message: "Deployment does not have minimum availability."},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded", Message: `ReplicaSet "nginx-deployment-123" has timed out progressing.`},
+			},
+		},
+	}
+	require.True(t, IsDeploymentRolloutStuck(deployment))
+	require.Equal(
+		t,
+		"Rollout of Deployment nginx-deployment is stuck\n"+
+			"  Available=False (MinimumReplicasUnavailable): Deployment does not have minimum availability.\n"+
+			`  Progressing=False (ProgressDeadlineExceeded): ReplicaSet "nginx-deployment-123" has timed out progressing.`,
+		NewDeploymentRolloutStuckError(deployment).Error(),
+	)
+
+	deployment.Status.Conditions[1] = appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "ReplicaSetUpdated"}
+	require.False(t, IsDeploymentRolloutStuck(deployment))
+}
+
+func TestIsStatefulSetAvailable(t *testing.T) {
+	t.Parallel()
+
+	partition := int32(1)
+	cases := []struct {
+		title          string
+		strategy       appsv1.StatefulSetUpdateStrategy
+		status         appsv1.StatefulSetStatus
+		expectedResult bool
+	}{
+		{
+			title:          "TestIsStatefulSetAvailable",
+			strategy:       appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			status:         appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 2, UpdatedReplicas: 2, CurrentRevision: "rev-2", UpdateRevision: "rev-2"},
+			expectedResult: true,
+		},
+		{
+			title:          "TestIsStatefulSetGenerationNotObserved",
+			strategy:       appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			status:         appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 2, UpdatedReplicas: 2, CurrentRevision: "rev-2", UpdateRevision: "rev-2"},
+			expectedResult: false,
+		},
+		{
+			title:          "TestIsStatefulSetReplicasNotReady",
+			strategy:       appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			status:         appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 1, UpdatedReplicas: 2, CurrentRevision: "rev-2", UpdateRevision: "rev-2"},
+			expectedResult: false,
+		},
+		{
+			title:          "TestIsStatefulSetRollingUpdateInProgress",
+			strategy:       appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			status:         appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 2, UpdatedReplicas: 1, CurrentRevision: "rev-1", UpdateRevision: "rev-2"},
+			expectedResult: false,
+		},
+		{
+			title: "TestIsStatefulSetPartitionUpdated",
+			strategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+			status:         appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 2, UpdatedReplicas: 1, CurrentRevision: "rev-1", UpdateRevision: "rev-2"},
+			expectedResult: true,
+		},
+		{
+			title:          "TestIsStatefulSetOnDelete",
+			strategy:       appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+			status:         appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 2, UpdatedReplicas: 0, CurrentRevision: "rev-1", UpdateRevision: "rev-2"},
+			expectedResult: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+			replicas := int32(2)
+			statefulSet := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, UpdateStrategy: tc.strategy},
+				Status:     tc.status,
+			}
+			actualResult := IsStatefulSetAvailable(statefulSet)
+			require.Equal(t, tc.expectedResult, actualResult)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+//go:build ignore
+// +build ignore
+
+// This program generates the Kubernetes OpenAPI schemas bundled with the k8s module for ValidateManifests, from the
+// swagger.json of the given Kubernetes releases. The schemas are trimmed down to what the validation needs (e.g.,
+// without the descriptions and the API paths) and gzipped to keep them small. To add a Kubernetes release, run:
+//
+// go run generate.go 1.22.0 1.23.0
+//
+// This downloads the k8s.io/kubernetes module of each release with `go mod download`, so it doesn't need a clone of
+// the Kubernetes repo.
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// keptFields are the fields of the schemas that the validation needs.
+var keptFields = map[string]bool{
+	"type":                            true,
+	"format":                          true,
+	"properties":                      true,
+	"items":                           true,
+	"additionalProperties":            true,
+	"$ref":                            true,
+	"required":                        true,
+	"x-kubernetes-group-version-kind": true,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: go run generate.go KUBERNETES_VERSION...")
+		os.Exit(1)
+	}
+	for _, version := range os.Args[1:] {
+		if err := generate(strings.TrimPrefix(version, "v")); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to generate the schemas of Kubernetes %s: %v\n", version, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// generate writes the trimmed schemas of the given Kubernetes release to kubernetes-<major>.<minor>.json.gz.
+func generate(version string) error {
+	out, err := exec.Command("go", "mod", "download", "-json", "k8s.io/kubernetes@v"+version).Output()
+	if err != nil {
+		return err
+	}
+	var module struct{ Dir string }
+	if err := json.Unmarshal(out, &module); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(module.Dir, "api", "openapi-spec", "swagger.json"))
+	if err != nil {
+		return err
+	}
+	var swagger struct {
+		Definitions map[string]map[string]interface{} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &swagger); err != nil {
+		return err
+	}
+
+	definitions := map[string]interface{}{}
+	for name, definition := range swagger.Definitions {
+		definitions[name] = trim(definition)
+	}
+
+	segments := strings.SplitN(version, ".", 3)
+	path := fmt.Sprintf("kubernetes-%s.%s.json.gz", segments[0], segments[1])
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer, err := gzip.NewWriterLevel(file, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(writer).Encode(map[string]interface{}{"definitions": definitions}); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Generated %s\n", path)
+	return nil
+}
+
+// trim returns a copy of the given schema with only the kept fields.
+func trim(schema map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for field, value := range schema {
+		if !keptFields[field] {
+			continue
+		}
+		switch field {
+		case "properties":
+			properties := map[string]interface{}{}
+			for name, property := range value.(map[string]interface{}) {
+				properties[name] = trim(property.(map[string]interface{}))
+			}
+			out[field] = properties
+		case "items", "additionalProperties":
+			if nested, ok := value.(map[string]interface{}); ok {
+				out[field] = trim(nested)
+			} else {
+				out[field] = value
+			}
+		default:
+			out[field] = value
+		}
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"time"
 
@@ -28,7 +29,23 @@ func GetSecretE(t testing.TestingT, options *KubectlOptions, secretName string)
 	if err != nil {
 		return nil, err
 	}
-	return clientset.CoreV1().Secrets(options.Namespace).Get(context.Background(), secretName, metav1.GetOptions{})
+	secret, err := clientset.CoreV1().Secrets(options.Namespace).Get(context.Background(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	markSecretDataSensitive(secret)
+	return secret, nil
+}
+
+// markSecretDataSensitive marks the data of the given secret as sensitive, both decoded and base64 encoded (as shown by
+// kubectl), so that it is redacted from all the log lines and error messages that follow.
+func markSecretDataSensitive(secret *corev1.Secret) {
+	for _, value := range secret.Data {
+		logger.MarkSensitive(string(value), base64.StdEncoding.EncodeToString(value))
+	}
+	for _, value := range secret.StringData {
+		logger.MarkSensitive(value, base64.StdEncoding.EncodeToString([]byte(value)))
+	}
 }
 
 // WaitUntilSecretAvailable waits until the secret is present on the cluster in cases where it is not immediately
@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// SummaryFileName is the name of the file in the output directory of a FileLogger that contains the results of the
+// tests. It uses the same name and format as the summary produced by terratest_log_parser.
+const SummaryFileName = "summary.log"
+
+// FileLogger is a TestLogger that logs each message to stdout, in the same format as Terratest, and additionally tees
+// it into a log file per test in the output directory, named after the test (e.g. OUTPUT_DIR/TestFoo.log, and
+// OUTPUT_DIR/TestFoo/subtest.log for subtests). Every time a test finishes, the summary file in the output directory is
+// rewritten with the results of all the tests so far. This gives the same layout as terratest_log_parser, but while the
+// tests are running, so the output of parallel tests is separated even when the test run is killed before the parser
+// could run.
+//
+// Use it with logger.New, e.g. by setting logger.Default = logger.New(fileLogger) in TestMain, and call Close once all
+// the tests have finished. The log file of each test is closed when the test finishes, so that a large suite doesn't
+// keep a file open per test; Close only closes the files of the tests that are still running.
+type FileLogger struct {
+	outputDir string
+
+	lock    sync.Mutex
+	files   map[string]*os.File
+	closed  map[string]bool
+	started map[string]time.Time
+	results []fileLoggerResult
+}
+
+// fileLoggerResult is the outcome of a single test, as recorded by a FileLogger.
+type fileLoggerResult struct {
+	testName string
+	status   string
+	duration time.Duration
+}
+
+// testResultReporter is implemented by testing.T, and is used to record the result of a test when it finishes.
+type testResultReporter interface {
+	Cleanup(func())
+	Failed() bool
+	Skipped() bool
+}
+
+// NewFileLogger returns a FileLogger that writes the per test log files and the summary into the given directory,
+// creating it if it does not exist yet.
+func NewFileLogger(outputDir string) (*FileLogger, error) {
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &FileLogger{
+		outputDir: outputDir,
+		files:     map[string]*os.File{},
+		closed:    map[string]bool{},
+		started:   map[string]time.Time{},
+	}, nil
+}
+
+func (l *FileLogger) Logf(t testing.TestingT, format string, args ...interface{}) {
+	var writer io.Writer = os.Stdout
+
+	file, err := l.getOrCreateFile(t)
+	if err == nil {
+		writer = io.MultiWriter(os.Stdout, file)
+	} else {
+		fmt.Fprintf(os.Stderr, "Error creating log file for test %s: %v\n", t.Name(), err)
+	}
+
+	DoLog(t, 3, writer, fmt.Sprintf(format, args...))
+}
+
+// Close writes the final summary and closes all the log files.
+func (l *FileLogger) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	summaryErr := l.writeSummary()
+
+	for testName, file := range l.files {
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("error closing log file for test %s: %v", testName, err)
+		}
+	}
+	l.files = map[string]*os.File{}
+
+	return summaryErr
+}
+
+// getOrCreateFile returns the log file of the given test, creating it on its first log message. The first time a test
+// logs, a cleanup function is also registered to record its result in the summary and close its log file when it
+// finishes. A test that logs again after that, e.g. from a cleanup function that runs later, reopens its log file in
+// append mode.
+func (l *FileLogger) getOrCreateFile(t testing.TestingT) (*os.File, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	testName := t.Name()
+	if file, hasFile := l.files[testName]; hasFile {
+		return file, nil
+	}
+
+	filename := filepath.Join(l.outputDir, testName+".log")
+	if l.closed[testName] {
+		file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		l.files[testName] = file
+		return file, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	l.files[testName] = file
+	l.started[testName] = time.Now()
+
+	if reporter, ok := t.(testResultReporter); ok {
+		reporter.Cleanup(func() {
+			l.recordResult(testName, reporter)
+		})
+	}
+
+	return file, nil
+}
+
+// recordResult stores the result of the given test, rewrites the summary and closes the log file of the test.
+func (l *FileLogger) recordResult(testName string, reporter testResultReporter) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if file, hasFile := l.files[testName]; hasFile {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing log file for test %s: %v\n", testName, err)
+		}
+		delete(l.files, testName)
+		l.closed[testName] = true
+	}
+
+	status := "PASS"
+	if reporter.Failed() {
+		status = "FAIL"
+	} else if reporter.Skipped() {
+		status = "SKIP"
+	}
+
+	l.results = append(l.results, fileLoggerResult{
+		testName: testName,
+		status:   status,
+		duration: time.Since(l.started[testName]),
+	})
+	delete(l.started, testName)
+
+	if err := l.writeSummary(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing test summary: %v\n", err)
+	}
+}
+
+// writeSummary rewrites the summary file with the results of the finished tests, in the order they finished, followed
+// by the tests that are still running. The caller must hold the lock.
+func (l *FileLogger) writeSummary() error {
+	lines := []string{}
+	for _, result := range l.results {
+		indent := strings.Repeat("    ", strings.Count(result.testName, "/"))
+		lines = append(lines, fmt.Sprintf("%s--- %s: %s (%.2fs)", indent, result.status, result.testName, result.duration.Seconds()))
+	}
+
+	running := []string{}
+	for testName := range l.started {
+		running = append(running, testName)
+	}
+	sort.Strings(running)
+	for _, testName := range running {
+		lines = append(lines, fmt.Sprintf("=== RUN   %s", testName))
+	}
+
+	// Write to a temporary file first, so that the summary is never left half written if the process is killed.
+	summaryPath := filepath.Join(l.outputDir, SummaryFileName)
+	tmpPath := summaryPath + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, summaryPath)
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLoggerReopensClosedFile(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	fileLogger, err := NewFileLogger(outputDir)
+	require.NoError(t, err)
+	l := New(fileLogger)
+
+	t.Run("subtest", func(t *testing.T) {
+		// Registered before the first log, so it runs after the log file is closed
+		t.Cleanup(func() {
+			l.Logf(t, "log from cleanup")
+		})
+		l.Logf(t, "subtest log")
+	})
+	require.NoError(t, fileLogger.Close())
+
+	subtestLog, err := ioutil.ReadFile(filepath.Join(outputDir, t.Name(), "subtest.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(subtestLog), "subtest log")
+	assert.Contains(t, string(subtestLog), "log from cleanup")
+}
+
+func TestFileLogger(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	fileLogger, err := NewFileLogger(outputDir)
+	require.NoError(t, err)
+	l := New(fileLogger)
+
+	l.Logf(t, "parent log")
+	t.Run("first", func(t *testing.T) {
+		l.Logf(t, "first subtest log")
+	})
+	t.Run("second", func(t *testing.T) {
+		l.Logf(t, "second subtest log")
+		t.Skip("skipping")
+	})
+
+	// The log files of the finished subtests are closed, while the one of the running test stays open
+	fileLogger.lock.Lock()
+	assert.Len(t, fileLogger.files, 1)
+	assert.Contains(t, fileLogger.files, t.Name())
+	fileLogger.lock.Unlock()
+
+	require.NoError(t, fileLogger.Close())
+
+	parentLog, err := ioutil.ReadFile(filepath.Join(outputDir, t.Name()+".log"))
+	require.NoError(t, err)
+	assert.Regexp(t, "^TestFileLogger .+ file_test.go:[0-9]+: parent log\n$", string(parentLog))
+
+	firstLog, err := ioutil.ReadFile(filepath.Join(outputDir, t.Name(), "first.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(firstLog), "first subtest log")
+	assert.NotContains(t, string(firstLog), "second subtest log")
+
+	summary, err := ioutil.ReadFile(filepath.Join(outputDir, SummaryFileName))
+	require.NoError(t, err)
+	assert.Regexp(t, `^    --- PASS: TestFileLogger/first \([0-9.]+s\)
+    --- SKIP: TestFileLogger/second \([0-9.]+s\)
+=== RUN   TestFileLogger
+$`, string(summary))
+}
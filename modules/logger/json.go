@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+const (
+	// LevelField is the field that can be attached with WithFields to set the level of a message logged by the JSON
+	// logger. Messages without this field are logged with DefaultLevel.
+	LevelField = "level"
+	// DefaultLevel is the level of the messages that do not have a LevelField attached.
+	DefaultLevel = "info"
+)
+
+// jsonLogger logs each message as a single JSON object per line.
+type jsonLogger struct {
+	writer io.Writer
+	lock   *sync.Mutex
+}
+
+// jsonRecord is the structure of a single line logged by the JSON logger.
+type jsonRecord struct {
+	Timestamp string `json:"timestamp"`
+	Test      string `json:"test"`
+	Caller    string `json:"caller"`
+	Level     string `json:"level"`
+	Message   string `json:"msg"`
+	Fields    Fields `json:"fields,omitempty"`
+}
+
+// NewJSONLogger returns a TestLogger that writes each message to the given writer as a single JSON object per line.
+// See JSON for more info.
+func NewJSONLogger(writer io.Writer) TestLogger {
+	return jsonLogger{writer: writer, lock: &sync.Mutex{}}
+}
+
+func (l jsonLogger) Logf(t testing.TestingT, format string, args ...interface{}) {
+	l.log(t, nil, fmt.Sprintf(format, args...))
+}
+
+func (l jsonLogger) LogfWithFields(t testing.TestingT, fields Fields, format string, args ...interface{}) {
+	l.log(t, fields, fmt.Sprintf(format, args...))
+}
+
+// log writes the record. It must be called directly from Logf or LogfWithFields, which in turn are called from
+// Logger.Logf, for the caller information to point to the code that is doing the logging.
+func (l jsonLogger) log(t testing.TestingT, fields Fields, message string) {
+	record := jsonRecord{
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Test:      t.Name(),
+		Caller:    CallerPrefix(4),
+		Level:     DefaultLevel,
+		Message:   Redact(message),
+	}
+
+	if len(fields) > 0 {
+		record.Fields = Fields{}
+		for key, value := range fields {
+			if key == LevelField {
+				record.Level = fmt.Sprint(value)
+				continue
+			}
+			if text, isString := value.(string); isString {
+				value = Redact(text)
+			}
+			record.Fields[key] = jsonFieldValue(value)
+		}
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		// One of the fields can't be encoded (e.g., a channel or a func), so fall back to their string representation.
+		for key, value := range record.Fields {
+			record.Fields[key] = fmt.Sprintf("%v", value)
+		}
+		line, err = json.Marshal(record)
+		if err != nil {
+			return
+		}
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	fmt.Fprintln(l.writer, string(line))
+}
+
+// jsonFieldValue converts values that have no useful JSON representation to strings.
+func jsonFieldValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case error:
+		return typed.Error()
+	case fmt.Stringer:
+		return typed.String()
+	default:
+		return value
+	}
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLogger(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	l := New(NewJSONLogger(&buffer))
+
+	l.Logf(t, "plain %s", "message")
+	l.WithFields(Fields{"command": "terraform", "attempt": 2}).
+		WithFields(Fields{LevelField: "warn", "error": fmt.Errorf("boom")}).
+		Logf(t, "with fields")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var plain map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &plain))
+	assert.Equal(t, t.Name(), plain["test"])
+	assert.Equal(t, DefaultLevel, plain["level"])
+	assert.Equal(t, "plain message", plain["msg"])
+	assert.Regexp(t, "^json_test.go:[0-9]+$", plain["caller"])
+	assert.NotEmpty(t, plain["timestamp"])
+	assert.NotContains(t, plain, "fields")
+
+	var withFields map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &withFields))
+	assert.Equal(t, "warn", withFields["level"])
+	assert.Equal(t, "with fields", withFields["msg"])
+	assert.Equal(t, map[string]interface{}{"command": "terraform", "attempt": float64(2), "error": "boom"}, withFields["fields"])
+}
+
+func TestWithFieldsIgnoredByPlainLoggers(t *testing.T) {
+	t.Parallel()
+
+	c := &customLogger{}
+	l := New(c).WithFields(Fields{"command": "terraform"})
+	l.Logf(t, "log output")
+
+	assert.Equal(t, []string{"log output"}, c.logs)
+}
@@ -39,19 +39,36 @@ var (
 	// TestingT can be used to use Go's testing.T to log. If this is used, but no testing.T is provided, it will fallback
 	// to Default.
 	TestingT = New(testingT{})
+	// JSON logs each message to stdout as a single JSON object per line, containing the timestamp, test name, caller,
+	// level, message and any structured fields attached with WithFields. This is useful to ingest the logs in a log
+	// pipeline without having to parse the human readable format.
+	JSON = New(NewJSONLogger(os.Stdout))
 )
 
 type TestLogger interface {
 	Logf(t testing.TestingT, format string, args ...interface{})
 }
 
+// Fields are structured key value pairs that are attached to log messages, such as the command being run or the
+// attempt number of a retry.
+type Fields map[string]interface{}
+
+// FieldLogger is a TestLogger that can also log structured fields. Loggers that do not implement this interface simply
+// ignore the fields attached with WithFields.
+type FieldLogger interface {
+	TestLogger
+	LogfWithFields(t testing.TestingT, fields Fields, format string, args ...interface{})
+}
+
 type Logger struct {
-	l TestLogger
+	l       TestLogger
+	fields  Fields
+	secrets *Redactor
 }
 
 func New(l TestLogger) *Logger {
 	return &Logger{
-		l,
+		l: l,
 	}
 }
 
@@ -67,7 +84,79 @@ func (l *Logger) Logf(t testing.TestingT, format string, args ...interface{}) {
 		return
 	}
 
-	l.l.Logf(t, format, args...)
+	// Format the message here, so that sensitive values are redacted before they reach the underlying logger.
+	message := l.redact(fmt.Sprintf(format, args...))
+
+	if fieldLogger, ok := l.l.(FieldLogger); ok {
+		fieldLogger.LogfWithFields(t, l.redactFields(), "%s", message)
+		return
+	}
+
+	l.l.Logf(t, "%s", message)
+}
+
+// WithFields returns a new Logger that attaches the given fields, in addition to the fields already attached to this
+// Logger, to every message it logs. The fields are only shown by loggers that implement FieldLogger, such as JSON. As
+// with Logf, this can be called on a nil Logger, in which case the fields are attached to the Default logger.
+func (l *Logger) WithFields(fields Fields) *Logger {
+	newLogger := l.clone()
+	for key, value := range fields {
+		newLogger.fields[key] = value
+	}
+	return newLogger
+}
+
+// WithSecrets returns a new Logger that redacts the given values, in addition to the values already redacted by this
+// Logger and the globally registered Secrets, from every message it logs. As with Logf, this can be called on a nil
+// Logger, in which case the values are redacted from the messages logged with the Default logger. Same as with
+// MarkSensitive, values shorter than 4 characters are not redacted, and a warning is printed to stderr once for each of
+// them.
+func (l *Logger) WithSecrets(values ...string) *Logger {
+	newLogger := l.clone()
+	newLogger.secrets = NewRedactor()
+	if l != nil {
+		newLogger.secrets.Add(l.secrets.Values()...)
+	}
+	newLogger.secrets.Add(values...)
+	return newLogger
+}
+
+// clone returns a copy of this Logger, falling back to the Default logger if this one is nil.
+func (l *Logger) clone() *Logger {
+	base := l
+	if base == nil || base.l == nil {
+		base = Default
+	}
+
+	newLogger := &Logger{l: base.l, fields: Fields{}}
+	if l != nil {
+		for key, value := range l.fields {
+			newLogger.fields[key] = value
+		}
+		newLogger.secrets = l.secrets
+	}
+	return newLogger
+}
+
+// redact replaces the sensitive values known to this Logger, as well as the global Secrets, in the given text.
+func (l *Logger) redact(text string) string {
+	return Secrets.Redact(l.secrets.Redact(text))
+}
+
+// redactFields returns the fields of this Logger with the sensitive values redacted from all the string values.
+func (l *Logger) redactFields() Fields {
+	if len(l.fields) == 0 {
+		return nil
+	}
+
+	fields := Fields{}
+	for key, value := range l.fields {
+		if text, isString := value.(string); isString {
+			value = l.redact(text)
+		}
+		fields[key] = value
+	}
+	return fields
 }
 
 // helper is used to mark this library as a "helper", and thus not appearing in the line numbers. testing.T implements
@@ -144,7 +233,7 @@ func DoLog(t testing.TestingT, callDepth int, writer io.Writer, args ...interfac
 	date := time.Now()
 	prefix := fmt.Sprintf("%s %s %s:", t.Name(), date.Format(time.RFC3339), CallerPrefix(callDepth+1))
 	allArgs := append([]interface{}{prefix}, args...)
-	fmt.Fprintln(writer, allArgs...)
+	fmt.Fprint(writer, Redact(fmt.Sprintln(allArgs...)))
 }
 
 // CallerPrefix returns the file and line number information about the methods that called this method, based on the current
@@ -0,0 +1,305 @@
+// Package logger/parser contains methods to parse and restructure log output from go testing and terratest
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/go-commons/files"
+	junitformatter "github.com/jstemmer/go-junit-report/formatter"
+	"github.com/sirupsen/logrus"
+)
+
+// FlakinessRun is the output directory of a previous run of terratest_log_parser, along with the commit that was tested
+// in that run. Runs without a commit are assumed to have tested the same commit.
+type FlakinessRun struct {
+	Dir    string `json:"dir"`
+	Commit string `json:"commit,omitempty"`
+}
+
+// FlakinessReport contains the results of each test across several runs.
+type FlakinessReport struct {
+	Runs  []FlakinessRun  `json:"runs"`
+	Tests []TestFlakiness `json:"tests"`
+}
+
+// TestFlakiness is the result of a single test across several runs. A test is flaky when it both passed and failed on the
+// same commit.
+type TestFlakiness struct {
+	Name     string  `json:"name"`
+	Passed   int     `json:"passed"`
+	Failed   int     `json:"failed"`
+	Skipped  int     `json:"skipped"`
+	PassRate float64 `json:"pass_rate"`
+	Flaky    bool    `json:"flaky"`
+	// The commits that the test both passed and failed on.
+	FlakyCommits []string `json:"flaky_commits,omitempty"`
+	// The subtests that failed in the runs where this test failed, which are usually the cause of the failure.
+	FailedSubtests []string `json:"failed_subtests,omitempty"`
+	// The result of the test in each run, in the order of the runs. Empty when the test did not run.
+	Results []string `json:"results"`
+}
+
+// AnalyzeFlakiness reads the test results from the report.xml and summary.log files in the output directories of the
+// given runs, and computes the pass rate of each test and whether it flipped between passing and failing on the same
+// commit.
+func AnalyzeFlakiness(logger *logrus.Logger, runs []FlakinessRun) (*FlakinessReport, error) {
+	report := &FlakinessReport{Runs: runs, Tests: []TestFlakiness{}}
+	lookup := map[string]*TestFlakiness{}
+	// test name -> commit -> set of results
+	resultsByCommit := map[string]map[string]map[string]bool{}
+
+	for runIndex, run := range runs {
+		results, failedSubtests, err := readRunResults(logger, run.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for testName, result := range results {
+			test, hasTest := lookup[testName]
+			if !hasTest {
+				test = &TestFlakiness{Name: testName, Results: make([]string, len(runs))}
+				lookup[testName] = test
+				resultsByCommit[testName] = map[string]map[string]bool{}
+			}
+			test.Results[runIndex] = result
+
+			switch result {
+			case "PASS":
+				test.Passed++
+			case "FAIL":
+				test.Failed++
+				test.FailedSubtests = appendUnique(test.FailedSubtests, failedSubtests[testName]...)
+			case "SKIP":
+				test.Skipped++
+			}
+
+			if resultsByCommit[testName][run.Commit] == nil {
+				resultsByCommit[testName][run.Commit] = map[string]bool{}
+			}
+			resultsByCommit[testName][run.Commit][result] = true
+		}
+	}
+
+	for testName, test := range lookup {
+		if test.Passed+test.Failed > 0 {
+			test.PassRate = float64(test.Passed) / float64(test.Passed+test.Failed)
+		}
+		for commit, results := range resultsByCommit[testName] {
+			if results["PASS"] && results["FAIL"] {
+				test.Flaky = true
+				test.FlakyCommits = append(test.FlakyCommits, commit)
+			}
+		}
+		sort.Strings(test.FlakyCommits)
+		report.Tests = append(report.Tests, *test)
+	}
+
+	// Flaky tests first, then the tests that fail the most.
+	sort.Slice(report.Tests, func(i, j int) bool {
+		a, b := report.Tests[i], report.Tests[j]
+		if a.Flaky != b.Flaky {
+			return a.Flaky
+		}
+		if a.PassRate != b.PassRate {
+			return a.PassRate < b.PassRate
+		}
+		return a.Name < b.Name
+	})
+
+	return report, nil
+}
+
+// readRunResults reads the result of each test from the output directory of a run. The results in report.xml take
+// precedence, while summary.log is used for the tests that are missing from the junit report (e.g. because it was not
+// generated) and to find the failed subtests of each failed test.
+func readRunResults(logger *logrus.Logger, dir string) (map[string]string, map[string][]string, error) {
+	results := map[string]string{}
+	failedSubtests := map[string][]string{}
+
+	summaryFilename := filepath.Join(dir, "summary.log")
+	reportFilename := filepath.Join(dir, "report.xml")
+	if !files.FileExists(summaryFilename) && !files.FileExists(reportFilename) {
+		return nil, nil, errors.WithStackTrace(NoTestResultsFound{Dir: dir})
+	}
+
+	if files.FileExists(summaryFilename) {
+		file, err := os.Open(summaryFilename)
+		if err != nil {
+			return nil, nil, errors.WithStackTrace(err)
+		}
+		defer file.Close()
+		if err := readSummaryResults(file, results, failedSubtests); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		logger.Warnf("No summary.log found in %s, so failed subtests will not be reported", dir)
+	}
+
+	if files.FileExists(reportFilename) {
+		contents, err := ioutil.ReadFile(reportFilename)
+		if err != nil {
+			return nil, nil, errors.WithStackTrace(err)
+		}
+		var suites junitformatter.JUnitTestSuites
+		if err := xml.Unmarshal(contents, &suites); err != nil {
+			return nil, nil, errors.WithStackTrace(err)
+		}
+		for _, suite := range suites.Suites {
+			for _, testCase := range suite.TestCases {
+				switch {
+				case testCase.Failure != nil:
+					results[testCase.Name] = "FAIL"
+				case testCase.SkipMessage != nil:
+					results[testCase.Name] = "SKIP"
+				default:
+					results[testCase.Name] = "PASS"
+				}
+			}
+		}
+	} else {
+		logger.Warnf("No report.xml found in %s, so only the results in summary.log will be used", dir)
+	}
+
+	return results, failedSubtests, nil
+}
+
+// readSummaryResults reads the test result lines from the summary log. Same as when breaking out the logs, the results
+// of subtests are nested under the result of their parent test, so a stack of TestResultMarkers is used to find the
+// parents of each failed subtest.
+func readSummaryResults(reader io.Reader, results map[string]string, failedSubtests map[string][]string) error {
+	testResultMarkers := TestResultMarkerStack{}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		data := scanner.Text()
+		indentLevel := len(getIndent(data))
+		testResultMarkers = testResultMarkers.removeDedentedTestResultMarkers(indentLevel)
+
+		match := regexResult.FindStringSubmatch(data)
+		if match == nil {
+			continue
+		}
+		result, testName := match[1], match[2]
+		results[testName] = result
+
+		if result == "FAIL" {
+			for _, marker := range testResultMarkers {
+				failedSubtests[marker.TestName] = appendUnique(failedSubtests[marker.TestName], testName)
+			}
+		}
+		testResultMarkers = testResultMarkers.push(TestResultMarker{TestName: testName, IndentLevel: indentLevel})
+	}
+	return errors.WithStackTrace(scanner.Err())
+}
+
+// appendUnique appends the values that are not in the list yet.
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			found = found || existing == value
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// StoreFlakinessReport stores the flakiness report in the output directory, as flakiness.json and as the human readable
+// flakiness.txt.
+func StoreFlakinessReport(logger *logrus.Logger, outputDir string, report *FlakinessReport) error {
+	if err := ensureDirectoryExists(logger, outputDir); err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(outputDir, "flakiness.json"), append(contents, '\n'), 0644); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	var text strings.Builder
+	WriteFlakinessText(&text, report)
+	if err := ioutil.WriteFile(filepath.Join(outputDir, "flakiness.txt"), []byte(text.String()), 0644); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
+
+// WriteFlakinessText writes the flakiness report in a human readable format to the given writer.
+func WriteFlakinessText(writer io.Writer, report *FlakinessReport) {
+	fmt.Fprintf(writer, "Analyzed %d runs:\n", len(report.Runs))
+	for _, run := range report.Runs {
+		if run.Commit == "" {
+			fmt.Fprintf(writer, "  %s\n", run.Dir)
+		} else {
+			fmt.Fprintf(writer, "  %s (commit %s)\n", run.Dir, run.Commit)
+		}
+	}
+
+	flaky := []TestFlakiness{}
+	for _, test := range report.Tests {
+		if test.Flaky {
+			flaky = append(flaky, test)
+		}
+	}
+
+	if len(flaky) == 0 {
+		fmt.Fprintf(writer, "\nNo flaky tests found.\n")
+	} else {
+		fmt.Fprintf(writer, "\nFlaky tests (passed and failed on the same commit):\n")
+		for _, test := range flaky {
+			fmt.Fprintf(writer, "  %s\n", test.Name)
+			if len(test.FlakyCommits) > 1 || test.FlakyCommits[0] != "" {
+				fmt.Fprintf(writer, "    commits: %s\n", strings.Join(test.FlakyCommits, ", "))
+			}
+			fmt.Fprintf(writer, "    results: %s\n", strings.Join(formatResults(test.Results), " "))
+			if len(test.FailedSubtests) > 0 {
+				fmt.Fprintf(writer, "    failed subtests: %s\n", strings.Join(test.FailedSubtests, ", "))
+			}
+		}
+	}
+
+	fmt.Fprintf(writer, "\nPass rate per test:\n")
+	for _, test := range report.Tests {
+		if test.Passed+test.Failed == 0 {
+			fmt.Fprintf(writer, "     n/a  (skipped %d)  %s\n", test.Skipped, test.Name)
+			continue
+		}
+		fmt.Fprintf(writer, "  %5.1f%%  (%d/%d)  %s\n", test.PassRate*100, test.Passed, test.Passed+test.Failed, test.Name)
+	}
+}
+
+// formatResults replaces the missing results of a test with a dash.
+func formatResults(results []string) []string {
+	formatted := []string{}
+	for _, result := range results {
+		if result == "" {
+			result = "-"
+		}
+		formatted = append(formatted, result)
+	}
+	return formatted
+}
+
+// NoTestResultsFound is an error that occurs when an output directory contains neither a report.xml nor a summary.log.
+type NoTestResultsFound struct {
+	Dir string
+}
+
+func (err NoTestResultsFound) Error() string {
+	return fmt.Sprintf("No report.xml or summary.log found in %s", err.Dir)
+}
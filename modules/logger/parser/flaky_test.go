@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRunDir(t *testing.T, summary string) string {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "summary.log"), []byte(summary), 0644))
+	return dir
+}
+
+func TestAnalyzeFlakiness(t *testing.T) {
+	t.Parallel()
+
+	logger := NewTestLogger(t)
+	passing := createRunDir(t, `--- PASS: TestStable (1.00s)
+--- PASS: TestFlaky (2.00s)
+    --- PASS: TestFlaky/Deploy (1.00s)
+    --- PASS: TestFlaky/Validate (1.00s)
+PASS
+`)
+	failing := createRunDir(t, `--- PASS: TestStable (1.00s)
+--- FAIL: TestFlaky (2.00s)
+    --- PASS: TestFlaky/Deploy (1.00s)
+    --- FAIL: TestFlaky/Validate (1.00s)
+--- FAIL: TestBroken (0.00s)
+FAIL
+`)
+	fixed := createRunDir(t, `--- PASS: TestStable (1.00s)
+--- PASS: TestFlaky (2.00s)
+    --- PASS: TestFlaky/Deploy (1.00s)
+    --- PASS: TestFlaky/Validate (1.00s)
+--- PASS: TestBroken (0.00s)
+PASS
+`)
+
+	report, err := AnalyzeFlakiness(logger, []FlakinessRun{
+		{Dir: passing, Commit: "abc"},
+		{Dir: failing, Commit: "abc"},
+		{Dir: fixed, Commit: "def"},
+	})
+	require.NoError(t, err)
+
+	tests := map[string]TestFlakiness{}
+	for _, test := range report.Tests {
+		tests[test.Name] = test
+	}
+
+	flaky := tests["TestFlaky"]
+	assert.True(t, flaky.Flaky)
+	assert.Equal(t, []string{"abc"}, flaky.FlakyCommits)
+	assert.InDelta(t, 2.0/3.0, flaky.PassRate, 0.001)
+	assert.Equal(t, []string{"PASS", "FAIL", "PASS"}, flaky.Results)
+	assert.Equal(t, []string{"TestFlaky/Validate"}, flaky.FailedSubtests)
+	assert.True(t, tests["TestFlaky/Validate"].Flaky)
+	assert.False(t, tests["TestFlaky/Deploy"].Flaky)
+
+	// Failing on one commit and passing on the next is a fix, not a flake
+	broken := tests["TestBroken"]
+	assert.False(t, broken.Flaky)
+	assert.Equal(t, []string{"", "FAIL", "PASS"}, broken.Results)
+	assert.Equal(t, 0.5, broken.PassRate)
+
+	assert.Equal(t, 1.0, tests["TestStable"].PassRate)
+
+	// Flaky tests are reported first
+	assert.True(t, report.Tests[0].Flaky)
+	assert.True(t, report.Tests[1].Flaky)
+	assert.False(t, report.Tests[2].Flaky)
+
+	var text strings.Builder
+	WriteFlakinessText(&text, report)
+	assert.Contains(t, text.String(), "  TestFlaky\n    commits: abc\n    results: PASS FAIL PASS\n    failed subtests: TestFlaky/Validate\n")
+	assert.Contains(t, text.String(), "   50.0%  (1/2)  TestBroken\n")
+}
+
+func TestAnalyzeFlakinessPrefersJunitReport(t *testing.T) {
+	t.Parallel()
+
+	logger := NewTestLogger(t)
+	dir := t.TempDir()
+	file := openFile(t, "./fixtures/panic_example.log")
+	defer file.Close()
+	SpawnParsers(logger, file, dir)
+
+	report, err := AnalyzeFlakiness(logger, []FlakinessRun{{Dir: dir}})
+	require.NoError(t, err)
+
+	results := map[string]string{}
+	for _, test := range report.Tests {
+		results[test.Name] = test.Results[0]
+	}
+	// The panicking test has no result line in the summary, but is marked as failed in the junit report
+	assert.Equal(t, "FAIL", results["TestLogCollectorCreatesAndWritesToFile"])
+	assert.Equal(t, "PASS", results["TestIsPanicLine/BaseCase"])
+}
+
+func TestAnalyzeFlakinessMissingResults(t *testing.T) {
+	t.Parallel()
+
+	_, err := AnalyzeFlakiness(NewTestLogger(t), []FlakinessRun{{Dir: t.TempDir()}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No report.xml or summary.log found")
+}
@@ -0,0 +1,204 @@
+// Package logger/parser contains methods to parse and restructure log output from go testing and terratest
+package parser
+
+import (
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/files"
+	junitparser "github.com/jstemmer/go-junit-report/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// htmlReport is the data rendered into report.html.
+type htmlReport struct {
+	Packages []*htmlPackage
+	Summary  []htmlLogLine
+	Passed   int
+	Failed   int
+	Skipped  int
+}
+
+// htmlPackage groups the tests of a single go package in the html report.
+type htmlPackage struct {
+	Name     string
+	Shard    string
+	Duration time.Duration
+	Tests    []*htmlTest
+}
+
+// htmlTest is a single test in the html report, with its subtests nested.
+type htmlTest struct {
+	Name     string
+	FullName string
+	Status   string
+	Duration time.Duration
+	Panicked bool
+	Log      []htmlLogLine
+	Subtests []*htmlTest
+}
+
+// htmlLogLine is a single line of a test log in the html report.
+type htmlLogLine struct {
+	Text  string
+	Panic bool
+}
+
+// storeHTMLReport takes a parsed Junit report and stores it as report.html in the output directory, along with the
+// logs of each test that were broken out into the output directory by the log parser.
+func storeHTMLReport(logger *logrus.Logger, outputDir string, report *junitparser.Report, shards reportShards) {
+	ensureDirectoryExists(logger, outputDir)
+	filename := filepath.Join(outputDir, "report.html")
+	f, err := os.Create(filename)
+	if err != nil {
+		logger.Errorf("Error making file %s for html report", filename)
+		return
+	}
+	defer f.Close()
+
+	err = htmlReportTemplate.Execute(f, buildHTMLReport(logger, outputDir, report, shards))
+	if err != nil {
+		logger.Errorf("Error formatting html report: %s", err)
+		return
+	}
+}
+
+// buildHTMLReport converts the flat list of tests in the junit report into a tree of tests per package.
+func buildHTMLReport(logger *logrus.Logger, outputDir string, report *junitparser.Report, shards reportShards) htmlReport {
+	result := htmlReport{
+		Summary: readHTMLLog(logger, filepath.Join(outputDir, "summary.log")),
+	}
+
+	for i, pkg := range report.Packages {
+		htmlPkg := &htmlPackage{Name: pkg.Name, Shard: shards.name(i), Duration: pkg.Duration}
+		lookup := map[string]*htmlTest{}
+
+		for _, test := range pkg.Tests {
+			htmlTest := getOrCreateHTMLTest(htmlPkg, lookup, test.Name)
+			htmlTest.Status = resultName(test.Result)
+			htmlTest.Duration = test.Duration
+			htmlTest.Log = readHTMLLog(logger, filepath.Join(outputDir, test.Name+".log"))
+			for _, line := range htmlTest.Log {
+				htmlTest.Panicked = htmlTest.Panicked || line.Panic
+			}
+
+			switch test.Result {
+			case junitparser.PASS:
+				result.Passed++
+			case junitparser.FAIL:
+				result.Failed++
+			case junitparser.SKIP:
+				result.Skipped++
+			}
+		}
+
+		result.Packages = append(result.Packages, htmlPkg)
+	}
+
+	return result
+}
+
+// getOrCreateHTMLTest returns the test with the given name from the lookup, creating it and all its parents in the tree
+// if they do not exist yet.
+func getOrCreateHTMLTest(pkg *htmlPackage, lookup map[string]*htmlTest, testName string) *htmlTest {
+	if test, hasTest := lookup[testName]; hasTest {
+		return test
+	}
+
+	test := &htmlTest{Name: testName, FullName: testName}
+	lookup[testName] = test
+
+	index := strings.LastIndex(testName, "/")
+	if index < 0 {
+		pkg.Tests = append(pkg.Tests, test)
+		return test
+	}
+
+	test.Name = testName[index+1:]
+	parent := getOrCreateHTMLTest(pkg, lookup, testName[:index])
+	parent.Subtests = append(parent.Subtests, test)
+	return test
+}
+
+// readHTMLLog reads the log file with the given name, if it exists, marking the lines of panics.
+func readHTMLLog(logger *logrus.Logger, filename string) []htmlLogLine {
+	if !files.FileExists(filename) {
+		return nil
+	}
+
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		logger.Errorf("Error reading log file %s for html report: %s", filename, err)
+		return nil
+	}
+
+	lines := []htmlLogLine{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n") {
+		lines = append(lines, htmlLogLine{Text: line, Panic: isPanicLine(line)})
+	}
+	return lines
+}
+
+// resultName returns the go test name of the given result.
+func resultName(result junitparser.Result) string {
+	switch result {
+	case junitparser.FAIL:
+		return "FAIL"
+	case junitparser.SKIP:
+		return "SKIP"
+	default:
+		return "PASS"
+	}
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>Test report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+details { margin: 0.2em 0 0.2em 1.2em; }
+summary { cursor: pointer; padding: 0.2em 0; }
+pre { background: #f6f8fa; padding: 0.8em; overflow-x: auto; font-size: 0.85em; margin: 0.4em 0 0.4em 1.2em; }
+.badge { display: inline-block; min-width: 3em; text-align: center; border-radius: 3px; padding: 0.1em 0.4em; font-size: 0.8em; font-weight: bold; color: #fff; }
+.PASS { background: #2da44e; }
+.FAIL { background: #cf222e; }
+.SKIP { background: #9a6700; }
+.PANIC { background: #8250df; }
+.duration { color: #666; font-size: 0.85em; }
+.panic { background: #ffebe9; color: #cf222e; font-weight: bold; }
+.totals span { margin-right: 1em; }
+</style>
+</head>
+<body>
+<h1>Test report</h1>
+<p class="totals">
+<span><span class="badge PASS">PASS</span> {{.Passed}}</span>
+<span><span class="badge FAIL">FAIL</span> {{.Failed}}</span>
+<span><span class="badge SKIP">SKIP</span> {{.Skipped}}</span>
+</p>
+{{range .Packages}}
+<h2>{{.Name}} <span class="duration">({{.Duration}}){{if .Shard}} shard {{.Shard}}{{end}}</span></h2>
+{{range .Tests}}{{template "test" .}}{{end}}
+{{end}}
+{{if .Summary}}
+<h2>Summary</h2>
+<pre>{{range .Summary}}{{if .Panic}}<span class="panic">{{.Text}}</span>{{else}}{{.Text}}{{end}}
+{{end}}</pre>
+{{end}}
+</body>
+</html>
+{{define "test"}}
+<details id="{{.FullName}}"{{if eq .Status "FAIL"}} open{{end}}>
+<summary><span class="badge {{.Status}}">{{.Status}}</span>{{if .Panicked}} <span class="badge PANIC">PANIC</span>{{end}} {{.Name}} <span class="duration">({{.Duration}})</span></summary>
+{{if .Log}}<pre>{{range .Log}}{{if .Panic}}<span class="panic">{{.Text}}</span>{{else}}{{.Text}}{{end}}
+{{end}}</pre>{{end}}
+{{range .Subtests}}{{template "test" .}}{{end}}
+</details>
+{{end}}
+`))
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLReport(t *testing.T) {
+	t.Parallel()
+
+	logger := NewTestLogger(t)
+	dir := t.TempDir()
+	file := openFile(t, "./fixtures/json_example.log")
+	defer file.Close()
+
+	SpawnParsersWithFormats(logger, file, dir, []ReportFormat{HTMLFormat})
+
+	assert.NoFileExists(t, filepath.Join(dir, "report.xml"))
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "report.html"))
+	require.NoError(t, err)
+	html := string(contents)
+
+	// Failing tests are expanded, and subtests are nested in their parent
+	assert.Regexp(t, `(?s)<details id="TestTableDriven" open>.*<details id="TestTableDriven/Passing">.*<details id="TestTableDriven/Failing" open>`, html)
+	assert.Contains(t, html, `<span class="badge SKIP">SKIP</span> TestSkipped`)
+	assert.Contains(t, html, "running Failing case")
+	assert.Contains(t, html, `<span class="badge FAIL">FAIL</span> 2`)
+}
+
+func TestGetOrCreateHTMLTestBuildsTree(t *testing.T) {
+	t.Parallel()
+
+	pkg := &htmlPackage{}
+	lookup := map[string]*htmlTest{}
+	getOrCreateHTMLTest(pkg, lookup, "TestSnafu/Situation/Normal")
+	getOrCreateHTMLTest(pkg, lookup, "TestSnafu/Other")
+	getOrCreateHTMLTest(pkg, lookup, "TestFoo")
+
+	require.Len(t, pkg.Tests, 2)
+	assert.Equal(t, "TestSnafu", pkg.Tests[0].Name)
+	assert.Equal(t, "TestFoo", pkg.Tests[1].Name)
+	require.Len(t, pkg.Tests[0].Subtests, 2)
+	assert.Equal(t, "Situation", pkg.Tests[0].Subtests[0].Name)
+	assert.Equal(t, "Normal", pkg.Tests[0].Subtests[0].Subtests[0].Name)
+	assert.Equal(t, "TestSnafu/Situation/Normal", pkg.Tests[0].Subtests[0].Subtests[0].FullName)
+}
@@ -70,3 +70,8 @@ func TestIntegrationNewGoExample(t *testing.T) {
 	t.Parallel()
 	testExample(t, "new_go_failing")
 }
+
+func TestIntegrationJSONExample(t *testing.T) {
+	t.Parallel()
+	testExample(t, "json")
+}
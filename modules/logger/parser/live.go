@@ -0,0 +1,201 @@
+// Package logger/parser contains methods to parse and restructure log output from go testing and terratest
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// LiveSummaryFileName is the name of the file in the output directory that is periodically rewritten in follow mode,
+// with the tests that are running, passed and failed so far.
+const LiveSummaryFileName = "live_summary.log"
+
+// liveTest is the state of a single test in the live summary.
+type liveTest struct {
+	name    string
+	status  string
+	started time.Time
+	ended   time.Time
+}
+
+// liveSummary tracks the state of the tests as the test output arrives, and periodically rewrites the live summary file
+// in the output directory.
+type liveSummary struct {
+	filename string
+	now      func() time.Time
+
+	lock     sync.Mutex
+	started  time.Time
+	finished bool
+	tests    map[string]*liveTest
+	order    []string
+}
+
+// newLiveSummary creates a live summary that is written to the given output directory.
+func newLiveSummary(outputDir string) *liveSummary {
+	return &liveSummary{
+		filename: filepath.Join(outputDir, LiveSummaryFileName),
+		now:      time.Now,
+		started:  time.Now(),
+		tests:    map[string]*liveTest{},
+	}
+}
+
+// follow reads the test output from the given reader, updating the state of the tests, and rewrites the live summary
+// every interval until the reader is drained. The summary is rewritten one last time when the reader is drained.
+func (summary *liveSummary) follow(logger *logrus.Logger, read io.Reader, isJSON bool, interval time.Duration) {
+	done := make(chan struct{})
+	var waitForWriter sync.WaitGroup
+	waitForWriter.Add(1)
+	go func() {
+		defer waitForWriter.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := summary.write(); err != nil {
+					logger.Errorf("Error writing live summary: %s", err)
+				}
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(read)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if isJSON {
+			summary.observeEvent(scanner.Bytes())
+		} else {
+			summary.observeLine(scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Errorf("Error reading test output for live summary: %s", err)
+	}
+	// Drain whatever is left, so that the other parsers are never blocked on the live summary.
+	io.Copy(ioutil.Discard, read)
+
+	close(done)
+	waitForWriter.Wait()
+
+	summary.lock.Lock()
+	summary.finished = true
+	summary.lock.Unlock()
+	if err := summary.write(); err != nil {
+		logger.Errorf("Error writing live summary: %s", err)
+	}
+}
+
+// observeLine updates the state of the tests from a line of `go test -v` output.
+func (summary *liveSummary) observeLine(data string) {
+	if match := regexStatus.FindStringSubmatch(data); match != nil {
+		summary.update(match[2], match[1], time.Time{})
+	} else if match := regexResult.FindStringSubmatch(data); match != nil {
+		summary.update(match[2], match[1], time.Time{})
+	}
+}
+
+// observeEvent updates the state of the tests from a line of `go test -json` output.
+func (summary *liveSummary) observeEvent(line []byte) {
+	var event TestEvent
+	if err := json.Unmarshal(line, &event); err != nil || event.Test == "" {
+		return
+	}
+	switch event.Action {
+	case "run", "pause", "cont", "pass", "fail", "skip":
+		summary.update(event.Test, strings.ToUpper(event.Action), event.Time)
+	}
+}
+
+// update records the given go test status (RUN, PAUSE, CONT, PASS, FAIL or SKIP) of a test, at the given time, or now
+// if the time is not known.
+func (summary *liveSummary) update(testName string, status string, at time.Time) {
+	summary.lock.Lock()
+	defer summary.lock.Unlock()
+
+	if at.IsZero() {
+		at = summary.now()
+	}
+
+	test, hasTest := summary.tests[testName]
+	if !hasTest {
+		test = &liveTest{name: testName, started: at}
+		summary.tests[testName] = test
+		summary.order = append(summary.order, testName)
+	}
+
+	switch status {
+	case "RUN", "CONT":
+		test.status = "RUNNING"
+	case "PAUSE":
+		test.status = "PAUSED"
+	default:
+		test.status = status
+		test.ended = at
+	}
+}
+
+// format renders the live summary: the counts of tests per status, followed by the running and paused tests, and then
+// the finished tests in the order they started.
+func (summary *liveSummary) format() string {
+	summary.lock.Lock()
+	defer summary.lock.Unlock()
+
+	now := summary.now()
+	counts := map[string]int{}
+	active := []string{}
+	finished := []string{}
+	for _, testName := range summary.order {
+		test := summary.tests[testName]
+		counts[test.status]++
+		if test.ended.IsZero() {
+			active = append(active, fmt.Sprintf("=== %s %s (%s)", test.status, test.name, now.Sub(test.started).Round(time.Second)))
+		} else {
+			indent := strings.Repeat("    ", strings.Count(test.name, "/"))
+			finished = append(finished, fmt.Sprintf("%s--- %s: %s (%.2fs)", indent, test.status, test.name, test.ended.Sub(test.started).Seconds()))
+		}
+	}
+
+	state := "Running"
+	if summary.finished {
+		state = "Finished"
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s for %s, last updated at %s\n", state, now.Sub(summary.started).Round(time.Second), now.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&builder, "RUNNING: %d  PAUSED: %d  PASS: %d  FAIL: %d  SKIP: %d\n", counts["RUNNING"], counts["PAUSED"], counts["PASS"], counts["FAIL"], counts["SKIP"])
+	if len(active) > 0 {
+		builder.WriteString("\n" + strings.Join(active, "\n") + "\n")
+	}
+	if len(finished) > 0 {
+		builder.WriteString("\n" + strings.Join(finished, "\n") + "\n")
+	}
+	return builder.String()
+}
+
+// write rewrites the live summary file. It is written to a temporary file first, so that readers never see a half
+// written summary.
+func (summary *liveSummary) write() error {
+	if err := os.MkdirAll(filepath.Dir(summary.filename), os.ModePerm); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	tmpFilename := summary.filename + ".tmp"
+	if err := ioutil.WriteFile(tmpFilename, []byte(summary.format()), 0644); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return errors.WithStackTrace(os.Rename(tmpFilename, summary.filename))
+}
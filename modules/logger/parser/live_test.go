@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveSummaryFormat(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	summary := newLiveSummary(t.TempDir())
+	summary.started = now
+	summary.now = func() time.Time { return now }
+
+	summary.observeLine("=== RUN   TestDeploy")
+	summary.observeLine("=== RUN   TestSnafu")
+	summary.observeLine("=== PAUSE TestSnafu")
+	summary.observeLine("=== RUN   TestFast")
+	summary.observeLine("TestDeploy 2021-01-01T10:00:00Z command.go:121: Running command terraform with args [apply]")
+	now = now.Add(2 * time.Second)
+	summary.observeLine("--- PASS: TestFast (2.00s)")
+	summary.observeEvent([]byte(`{"Time":"2021-01-01T10:00:00Z","Action":"run","Test":"TestJSON"}`))
+	summary.observeEvent([]byte(`{"Time":"2021-01-01T10:00:01.5Z","Action":"fail","Test":"TestJSON","Elapsed":1.5}`))
+	summary.observeEvent([]byte(`{"Time":"2021-01-01T10:00:01.5Z","Action":"output","Output":"FAIL\n"}`))
+	now = now.Add(time.Minute)
+
+	assert.Equal(t, `Running for 1m2s, last updated at 2021-01-01T10:01:02Z
+RUNNING: 1  PAUSED: 1  PASS: 1  FAIL: 1  SKIP: 0
+
+=== RUNNING TestDeploy (1m2s)
+=== PAUSED TestSnafu (1m2s)
+
+--- PASS: TestFast (2.00s)
+--- FAIL: TestJSON (1.50s)
+`, summary.format())
+}
+
+func TestSpawnFollowingParsersWritesLiveSummaryWhileRunning(t *testing.T) {
+	t.Parallel()
+
+	logger := NewTestLogger(t)
+	dir := t.TempDir()
+	reader, writer := io.Pipe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		SpawnFollowingParsers(logger, reader, dir, []ReportFormat{JUnitFormat}, 10*time.Millisecond)
+	}()
+
+	_, err := io.WriteString(writer, "=== RUN   TestLongRunning\nTestLongRunning 2021-01-01T10:00:00Z command.go:121: Running command terraform\n")
+	require.NoError(t, err)
+
+	// The live summary and the log of the test are written before the test run ends
+	assert.Eventually(t, func() bool {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, LiveSummaryFileName))
+		return err == nil && regexp.MustCompile(`(?s)^Running for .*RUNNING: 1 .*=== RUNNING TestLongRunning`).Match(contents)
+	}, 5*time.Second, 10*time.Millisecond)
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "TestLongRunning.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "Running command terraform")
+
+	_, err = io.WriteString(writer, "--- PASS: TestLongRunning (1.00s)\nPASS\nok  	github.com/gruntwork-io/terratest/test	1.000s\n")
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	<-done
+
+	contents, err = ioutil.ReadFile(filepath.Join(dir, LiveSummaryFileName))
+	require.NoError(t, err)
+	assert.Regexp(t, `(?s)^Finished for .*PASS: 1 .*--- PASS: TestLongRunning`, string(contents))
+	assert.FileExists(t, filepath.Join(dir, "report.xml"))
+}
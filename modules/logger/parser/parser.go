@@ -8,33 +8,124 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	junitparser "github.com/jstemmer/go-junit-report/parser"
 	"github.com/sirupsen/logrus"
 )
 
+// ReportFormat is a format of the test report that the parsers produce in the output directory, in addition to the
+// logs broken out by test and the summary log.
+type ReportFormat string
+
+const (
+	// JUnitFormat produces a junit XML report named report.xml, to be consumed by a CI engine.
+	JUnitFormat ReportFormat = "junit"
+	// HTMLFormat produces a self contained HTML report named report.html, with the tests in a collapsible tree along
+	// with their logs.
+	HTMLFormat ReportFormat = "html"
+)
+
 // SpawnParsers will spawn the log parser and junit report parsers off of a single reader.
+// The format of the input is detected automatically: both the plain text output of `go test -v` and the JSON output of
+// `go test -json` are supported.
 func SpawnParsers(logger *logrus.Logger, reader io.Reader, outputDir string) {
+	SpawnParsersWithFormats(logger, reader, outputDir, []ReportFormat{JUnitFormat})
+}
+
+// SpawnParsersWithFormats is the same as SpawnParsers, but produces the test report in each of the given formats
+// instead of only the junit XML report.
+func SpawnParsersWithFormats(logger *logrus.Logger, reader io.Reader, outputDir string, formats []ReportFormat) {
+	report := parseTestOutput(logger, reader, outputDir, 0)
+	if report == nil {
+		return
+	}
+	storeReports(logger, outputDir, report, nil, formats)
+}
+
+// SpawnFollowingParsers is the same as SpawnParsersWithFormats, but is meant to follow the output of a test run while it
+// is in progress (e.g. when piping `go test` into the parser). In addition to the logs broken out by test, which are
+// written as the lines arrive, it rewrites live_summary.log in the output directory every refreshInterval with the
+// tests that are running, passed and failed so far.
+func SpawnFollowingParsers(logger *logrus.Logger, reader io.Reader, outputDir string, formats []ReportFormat, refreshInterval time.Duration) {
+	report := parseTestOutput(logger, reader, outputDir, refreshInterval)
+	if report == nil {
+		return
+	}
+	storeReports(logger, outputDir, report, nil, formats)
+}
+
+// parseTestOutput runs the log parser and the junit report parser off of a single reader, breaking out the logs by test
+// into the output directory, and returns the parsed junit report. Returns nil if the junit report could not be parsed.
+// When liveSummaryInterval is positive, the live summary is also rewritten at that interval while the output is read.
+func parseTestOutput(logger *logrus.Logger, reader io.Reader, outputDir string, liveSummaryInterval time.Duration) *junitparser.Report {
+	bufferedReader := bufio.NewReader(reader)
+	isJSON := isTestEventStream(bufferedReader)
+	if isJSON {
+		logger.Infof("Detected go test -json output")
+	}
+
 	forkedReader, forkedWriter := io.Pipe()
-	teedReader := io.TeeReader(reader, forkedWriter)
+	var input io.Reader = bufferedReader
+	var liveWriter *io.PipeWriter
+	var report *junitparser.Report
 	var waitForParsers sync.WaitGroup
+
+	if liveSummaryInterval > 0 {
+		var liveReader *io.PipeReader
+		liveReader, liveWriter = io.Pipe()
+		input = io.TeeReader(bufferedReader, liveWriter)
+		waitForParsers.Add(1)
+		go func() {
+			defer waitForParsers.Done()
+			newLiveSummary(outputDir).follow(logger, liveReader, isJSON, liveSummaryInterval)
+		}()
+	}
+
 	waitForParsers.Add(2)
 	go func() {
-		// close pipe writer, because this section drains the tee reader indicating reader is done draining
+		// close pipe writers, because this section drains the reader indicating reader is done draining
 		defer forkedWriter.Close()
+		if liveWriter != nil {
+			defer liveWriter.Close()
+		}
 		defer waitForParsers.Done()
-		parseAndStoreTestOutput(logger, teedReader, outputDir)
+		if isJSON {
+			// The junit report parser only understands plain text, so feed it the output reconstructed from the events.
+			parseAndStoreTestEvents(logger, input, outputDir, forkedWriter)
+		} else {
+			parseAndStoreTestOutput(logger, io.TeeReader(input, forkedWriter), outputDir)
+		}
 	}()
 	go func() {
 		defer waitForParsers.Done()
-		report, err := junitparser.Parse(forkedReader, "")
+		parsedReport, err := junitparser.Parse(forkedReader, "")
 		if err == nil {
-			storeJunitReport(logger, outputDir, report)
+			report = parsedReport
 		} else {
 			logger.Errorf("Error parsing test output into junit report: %s", err)
 		}
 	}()
 	waitForParsers.Wait()
+	return report
+}
+
+// storeReports stores the given report in each of the given formats in the output directory. The reports are stored
+// once both parsers are done, as some formats include the logs broken out by test. The timing report goes first, as it
+// adds the slowest tests and stages to the summary. When the report was merged from several shards, shards records the
+// shard each package came from.
+func storeReports(logger *logrus.Logger, outputDir string, report *junitparser.Report, shards reportShards, formats []ReportFormat) {
+	storeTimingReport(logger, outputDir, report, shards)
+	for _, format := range formats {
+		switch format {
+		case JUnitFormat:
+			storeJunitReport(logger, outputDir, report, shards)
+		case HTMLFormat:
+			storeHTMLReport(logger, outputDir, report, shards)
+		default:
+			logger.Errorf("Unknown report format: %s", format)
+		}
+	}
 }
 
 // RegEx for parsing test status lines. Pulled from jstemmer/go-junit-report
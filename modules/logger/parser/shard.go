@@ -0,0 +1,158 @@
+// Package logger/parser contains methods to parse and restructure log output from go testing and terratest
+package parser
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	junitparser "github.com/jstemmer/go-junit-report/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// Shard is the test output of one of the machines that a sharded test suite ran on.
+type Shard struct {
+	// Name identifies the shard in the merged reports, e.g. the name of its log file.
+	Name   string
+	Reader io.Reader
+}
+
+// reportShards records the shard each package of a merged junit report came from, by the index of the package in the
+// report.
+type reportShards []string
+
+// name returns the name of the shard of the package at the given index, or an empty string if the report was not
+// merged from shards.
+func (shards reportShards) name(index int) string {
+	if index < len(shards) {
+		return shards[index]
+	}
+	return ""
+}
+
+// SpawnShardParsers is the same as SpawnParsersWithFormats, but merges the test output of several shards of a test
+// suite into a single output directory. The logs of each test are broken out into the output directory as usual, the
+// summary log contains the summary of each shard under a `=== SHARD: name` header, and the reports contain the tests of
+// all the shards, annotated with the shard they ran in.
+func SpawnShardParsers(logger *logrus.Logger, shards []Shard, outputDir string, formats []ReportFormat) {
+	if err := ensureDirectoryExists(logger, outputDir); err != nil {
+		return
+	}
+
+	merged := &junitparser.Report{}
+	packageShards := reportShards{}
+	summary := strings.Builder{}
+	writtenLogs := map[string]bool{}
+
+	for _, shard := range uniqueShardNames(shards) {
+		logger.Infof("Parsing test output of shard %s", shard.Name)
+
+		// Each shard is parsed into its own directory first, as the summaries of the shards would overwrite each other.
+		shardDir, err := ioutil.TempDir(outputDir, ".shard-")
+		if err != nil {
+			logger.Errorf("Error creating directory for shard %s: %s", shard.Name, err)
+			continue
+		}
+		report := parseTestOutput(logger, shard.Reader, shardDir, 0)
+		if err := mergeShardLogs(logger, shard.Name, shardDir, outputDir, writtenLogs, &summary); err != nil {
+			logger.Errorf("Error merging logs of shard %s: %s", shard.Name, err)
+		}
+		if err := os.RemoveAll(shardDir); err != nil {
+			logger.Errorf("Error removing directory %s: %s", shardDir, err)
+		}
+
+		if report == nil {
+			continue
+		}
+		for _, pkg := range report.Packages {
+			merged.Packages = append(merged.Packages, pkg)
+			packageShards = append(packageShards, shard.Name)
+		}
+	}
+
+	summaryFilename := filepath.Join(outputDir, "summary.log")
+	if err := ioutil.WriteFile(summaryFilename, []byte(summary.String()), 0644); err != nil {
+		logger.Errorf("Error writing summary log %s: %s", summaryFilename, err)
+		return
+	}
+	storeReports(logger, outputDir, merged, packageShards, formats)
+}
+
+// uniqueShardNames returns the shards with names that are unique, so that they can be told apart in the reports. Shards
+// without a name are named after their position, and duplicate names get a numeric suffix.
+func uniqueShardNames(shards []Shard) []Shard {
+	seen := map[string]int{}
+	unique := []Shard{}
+	for i, shard := range shards {
+		name := shard.Name
+		if name == "" {
+			name = fmt.Sprintf("shard-%d", i+1)
+		}
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, seen[name])
+		}
+		unique = append(unique, Shard{Name: name, Reader: shard.Reader})
+	}
+	return unique
+}
+
+// mergeShardLogs moves the logs broken out by test from the shard directory to the output directory, appending to the
+// logs of the same test from previous shards (e.g. when a test was retried on another machine). The summary log of the
+// shard is appended to the summary under a header with the name of the shard. Logs in the output directory that were
+// not written by a previous shard in writtenLogs are overwritten, so that stale logs from previous runs are discarded.
+func mergeShardLogs(
+	logger *logrus.Logger,
+	shardName string,
+	shardDir string,
+	outputDir string,
+	writtenLogs map[string]bool,
+	summary *strings.Builder,
+) error {
+	return filepath.Walk(shardDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(shardDir, path)
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+
+		if relPath == "summary.log" {
+			fmt.Fprintf(summary, "=== SHARD: %s\n", shardName)
+			summary.Write(contents)
+			return nil
+		}
+
+		filename := filepath.Join(outputDir, relPath)
+		if err := ensureDirectoryExists(logger, filepath.Dir(filename)); err != nil {
+			return err
+		}
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if writtenLogs[relPath] {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		writtenLogs[relPath] = true
+
+		file, err := os.OpenFile(filename, flags, 0644)
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+		defer file.Close()
+		if _, err := file.Write(contents); err != nil {
+			return errors.WithStackTrace(err)
+		}
+		return nil
+	})
+}
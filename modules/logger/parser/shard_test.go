@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpawnShardParsers(t *testing.T) {
+	t.Parallel()
+
+	logger := NewTestLogger(t)
+	dir := t.TempDir()
+	basicFile := openFile(t, "./fixtures/basic_example.log")
+	defer basicFile.Close()
+	jsonFile := openFile(t, "./fixtures/json_example.log")
+	defer jsonFile.Close()
+
+	shards := []Shard{
+		{Name: "shard-a", Reader: basicFile},
+		{Name: "shard-b", Reader: jsonFile},
+	}
+	SpawnShardParsers(logger, shards, dir, []ReportFormat{JUnitFormat})
+
+	// The logs of the tests of both shards are broken out into the same directory, without the temporary shard folders
+	assert.FileExists(t, filepath.Join(dir, "TestStackPush.log"))
+	assert.FileExists(t, filepath.Join(dir, "TestTableDriven", "Failing.log"))
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	for _, file := range files {
+		assert.False(t, strings.HasPrefix(file.Name(), ".shard-"), file.Name())
+	}
+
+	summary, err := ioutil.ReadFile(filepath.Join(dir, "summary.log"))
+	require.NoError(t, err)
+	assert.Regexp(t, `(?s)^=== SHARD: shard-a\n.*--- PASS: TestStackPush.*=== SHARD: shard-b\n.*--- FAIL: TestTableDriven.*Slowest tests:`, string(summary))
+	assert.Contains(t, string(summary), "TestTableDriven [shard shard-b]")
+
+	report, err := ioutil.ReadFile(filepath.Join(dir, "report.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(report), `<property name="shard" value="shard-a"></property>`)
+	assert.Contains(t, string(report), `<property name="shard" value="shard-b"></property>`)
+	assert.Contains(t, string(report), `name="TestStackPush"`)
+	assert.Contains(t, string(report), `name="TestTableDriven/Failing"`)
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "timing.json"))
+	require.NoError(t, err)
+	var timing TimingReport
+	require.NoError(t, json.Unmarshal(contents, &timing))
+	shardOfTest := map[string]string{}
+	for _, test := range timing.Tests {
+		shardOfTest[test.Name] = test.Shard
+	}
+	assert.Equal(t, "shard-a", shardOfTest["TestStackPush"])
+	assert.Equal(t, "shard-b", shardOfTest["TestTableDriven"])
+}
+
+func TestUniqueShardNames(t *testing.T) {
+	t.Parallel()
+
+	shards := uniqueShardNames([]Shard{{Name: "test"}, {Name: "test"}, {}, {Name: "other"}})
+
+	names := []string{}
+	for _, shard := range shards {
+		names = append(names, shard.Name)
+	}
+	assert.Equal(t, []string{"test", "test-2", "shard-3", "other"}, names)
+}
@@ -2,6 +2,9 @@
 package parser
 
 import (
+	"bytes"
+	"encoding/xml"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -92,8 +95,9 @@ func ensureDirectoryExists(logger *logrus.Logger, dirName string) error {
 	return nil
 }
 
-// storeJunitReport takes a parsed Junit report and stores it as report.xml in the output directory
-func storeJunitReport(logger *logrus.Logger, outputDir string, report *junitparser.Report) {
+// storeJunitReport takes a parsed Junit report and stores it as report.xml in the output directory. When the report was
+// merged from several shards, each test suite gets a shard property recording the shard it came from.
+func storeJunitReport(logger *logrus.Logger, outputDir string, report *junitparser.Report, shards reportShards) {
 	ensureDirectoryExists(logger, outputDir)
 	filename := filepath.Join(outputDir, "report.xml")
 	f, err := os.Create(filename)
@@ -103,9 +107,41 @@ func storeJunitReport(logger *logrus.Logger, outputDir string, report *junitpars
 	}
 	defer f.Close()
 
-	err = junitformatter.JUnitReportXML(report, false, "", f)
+	if len(shards) == 0 {
+		err = junitformatter.JUnitReportXML(report, false, "", f)
+	} else {
+		err = writeShardedJunitReport(report, shards, f)
+	}
 	if err != nil {
 		logger.Errorf("Error formatting junit xml report: %s", err)
 		return
 	}
 }
+
+// writeShardedJunitReport formats the report with the junit formatter, and adds the shard property to each test suite
+// before writing it to the given writer.
+func writeShardedJunitReport(report *junitparser.Report, shards reportShards, writer io.Writer) error {
+	var buffer bytes.Buffer
+	if err := junitformatter.JUnitReportXML(report, true, "", &buffer); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	var suites junitformatter.JUnitTestSuites
+	if err := xml.Unmarshal(buffer.Bytes(), &suites); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	// The formatter outputs one test suite per package, in order.
+	for i := range suites.Suites {
+		property := junitformatter.JUnitProperty{Name: "shard", Value: shards.name(i)}
+		suites.Suites[i].Properties = append(suites.Suites[i].Properties, property)
+	}
+
+	contents, err := xml.MarshalIndent(suites, "", "\t")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if _, err := io.WriteString(writer, xml.Header+string(contents)+"\n"); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Package logger/parser contains methods to parse and restructure log output from go testing and terratest
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestEvent is a single event in the output of `go test -json` (see `go doc test2json`). Unlike the plain text output,
+// every output line is attributed to the test that produced it, so no heuristics are needed to break out the logs.
+type TestEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// isTestEventStream peeks at the first non whitespace character of the reader to detect if it contains the output of
+// `go test -json`, which is a stream of JSON objects, as opposed to the plain text output of `go test -v`. This does
+// not consume any data from the reader.
+func isTestEventStream(reader *bufio.Reader) bool {
+	for size := 1; size <= reader.Size(); size++ {
+		data, err := reader.Peek(size)
+		if err != nil {
+			return false
+		}
+		if char := rune(data[size-1]); !unicode.IsSpace(char) {
+			return char == '{'
+		}
+	}
+	return false
+}
+
+// parseAndStoreTestEvents is the equivalent of parseAndStoreTestOutput for the output of `go test -json`. It breaks out
+// the output of each test into files under the outputDir, named by test name, and collects the test result lines and
+// the package level output under a summary log file named `summary.log`. The plain text output reconstructed from the
+// events is written to textOutput, so that it can be fed to the junit report parser.
+func parseAndStoreTestEvents(
+	logger *logrus.Logger,
+	read io.Reader,
+	outputDir string,
+	textOutput io.Writer,
+) {
+	logWriter := LogWriter{
+		lookup:    make(map[string]*os.File),
+		outputDir: outputDir,
+	}
+	defer logWriter.closeFiles(logger)
+
+	// test2json may split long lines over multiple output events, so buffer incomplete lines per test.
+	partialLines := map[string]string{}
+
+	scanner := bufio.NewScanner(read)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var event TestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			// Non JSON lines can be interleaved in the stream, e.g. build errors, so treat them as package level output.
+			logger.Warnf("Found line that is not a go test event: %s", line)
+			event = TestEvent{Action: "output", Output: string(line) + "\n"}
+		}
+
+		if event.Action != "output" {
+			continue
+		}
+		if _, err := io.WriteString(textOutput, event.Output); err != nil {
+			logger.Errorf("Error forwarding test output to the junit report parser: %s", err)
+		}
+
+		text := partialLines[event.Test] + event.Output
+		lines := strings.Split(text, "\n")
+		partialLines[event.Test] = lines[len(lines)-1]
+		for _, data := range lines[:len(lines)-1] {
+			writeTestEventLine(logger, logWriter, event.Test, data)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Fatalf("Error reading from Reader: %s", err)
+	}
+
+	for testName, data := range partialLines {
+		if data != "" {
+			writeTestEventLine(logger, logWriter, testName, data)
+		}
+	}
+}
+
+// writeTestEventLine writes a single line of output, attributed to the given test by go test, to the log files. Lines
+// that do not belong to a test, such as the package result, go to the summary.
+func writeTestEventLine(logger *logrus.Logger, logWriter LogWriter, testName string, data string) {
+	switch {
+	case testName == "":
+		logWriter.writeLog(logger, "summary", data)
+
+	case isResultLine(data):
+		// go test -v prints the result lines of subtests nested under the result line of their parent, so, same as for
+		// the plain text output, roll them up to the logs of all the parents.
+		resultTestName := getTestNameFromResultLine(data)
+		if resultTestName != testName && !strings.HasPrefix(resultTestName, testName+"/") {
+			logWriter.writeLog(logger, testName, data)
+		}
+		parts := strings.Split(resultTestName, "/")
+		for i := 1; i <= len(parts); i++ {
+			logWriter.writeLog(logger, strings.Join(parts[:i], "/"), data)
+		}
+		logWriter.writeLog(logger, "summary", data)
+
+	case isPanicLine(data):
+		logWriter.writeLog(logger, testName, data)
+		logWriter.writeLog(logger, "summary", data)
+
+	default:
+		logWriter.writeLog(logger, testName, data)
+	}
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"bufio"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTestEventStream(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		in   string
+		out  bool
+	}{
+		{"JSONEvents", `{"Action":"run","Test":"TestSnafu"}`, true},
+		{"JSONEventsWithLeadingWhitespace", "\n  {\"Action\":\"run\"}", true},
+		{"PlainTextStatusLine", "=== RUN   TestSnafu", false},
+		{"PlainTextLogLine", "TestSnafu 2019-01-01T00:00:00Z test.go:10: {\"key\": \"value\"}", false},
+		{"EmptyString", "", false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			reader := bufio.NewReader(strings.NewReader(testCase.in))
+			assert.Equal(t, testCase.out, isTestEventStream(reader))
+
+			// Detection must not consume any data
+			rest, err := ioutil.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.in, string(rest))
+		})
+	}
+}
+
+func TestParseAndStoreTestEventsJoinsSplitLines(t *testing.T) {
+	t.Parallel()
+
+	logger := NewTestLogger(t)
+	dir := t.TempDir()
+	events := strings.Join([]string{
+		`{"Action":"output","Test":"TestSnafu","Output":"=== RUN   TestSnafu\n"}`,
+		`{"Action":"output","Test":"TestSnafu","Output":"a line that was "}`,
+		`{"Action":"output","Test":"TestSnafu","Output":"split in two\n"}`,
+		`{"Action":"output","Test":"TestSnafu","Output":"--- PASS: TestSnafu (0.00s)\n"}`,
+		`{"Action":"pass","Test":"TestSnafu"}`,
+		`{"Action":"output","Output":"PASS\n"}`,
+	}, "\n")
+
+	var text strings.Builder
+	parseAndStoreTestEvents(logger, strings.NewReader(events), dir, &text)
+
+	testLog, err := ioutil.ReadFile(filepath.Join(dir, "TestSnafu.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "=== RUN   TestSnafu\na line that was split in two\n--- PASS: TestSnafu (0.00s)\n", string(testLog))
+
+	summary, err := ioutil.ReadFile(filepath.Join(dir, "summary.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "--- PASS: TestSnafu (0.00s)\nPASS\n", string(summary))
+
+	assert.Equal(t, "=== RUN   TestSnafu\na line that was split in two\n--- PASS: TestSnafu (0.00s)\nPASS\n", text.String())
+}
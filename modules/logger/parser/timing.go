@@ -0,0 +1,207 @@
+// Package logger/parser contains methods to parse and restructure log output from go testing and terratest
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/files"
+	junitparser "github.com/jstemmer/go-junit-report/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// slowestEntriesInSummary is the number of tests and stages listed in the slowest sections of the summary.
+const slowestEntriesInSummary = 10
+
+// RegEx for parsing the log lines of test_structure.RunTestStage and the terratest log line prefix. This must be modified
+// when `logger.DoLog` or `test_structure.RunTestStage` change.
+var (
+	regexLogLinePrefix = regexp.MustCompile(`^(\S+) (\d{4}-\d{2}-\d{2}T\S+) \S+:\d+: `)
+	regexStageStart    = regexp.MustCompile(`environment variable is not set, so executing stage '(.+)'\.$`)
+	regexStageSkip     = regexp.MustCompile(`, so skipping stage '(.+)'\.$`)
+	regexStageEnd      = regexp.MustCompile(`Finished stage '(.+)' in (\S+)\.$`)
+)
+
+// TimingReport contains the durations of all the tests and of the stages they ran with test_structure.RunTestStage. It
+// is stored as timing.json in the output directory.
+type TimingReport struct {
+	Tests []TestTiming `json:"tests"`
+}
+
+// TestTiming is the duration of a single test and its stages.
+type TestTiming struct {
+	Name            string        `json:"name"`
+	Package         string        `json:"package"`
+	Shard           string        `json:"shard,omitempty"`
+	Status          string        `json:"status"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Stages          []StageTiming `json:"stages,omitempty"`
+}
+
+// StageTiming is the duration of a single stage of a test. Skipped stages have no duration.
+type StageTiming struct {
+	Name            string  `json:"name"`
+	Skipped         bool    `json:"skipped"`
+	Start           string  `json:"start,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// storeTimingReport computes the durations of the tests in the junit report, along with the durations of the stages
+// found in their logs, and stores them as timing.json in the output directory. It also appends the slowest tests and
+// stages to the summary log.
+func storeTimingReport(logger *logrus.Logger, outputDir string, report *junitparser.Report, shards reportShards) {
+	timingReport := buildTimingReport(logger, outputDir, report, shards)
+
+	ensureDirectoryExists(logger, outputDir)
+	filename := filepath.Join(outputDir, "timing.json")
+	contents, err := json.MarshalIndent(timingReport, "", "  ")
+	if err != nil {
+		logger.Errorf("Error formatting timing report: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(filename, append(contents, '\n'), 0644); err != nil {
+		logger.Errorf("Error writing timing report %s: %s", filename, err)
+		return
+	}
+
+	summaryFilename := filepath.Join(outputDir, "summary.log")
+	summary, err := os.OpenFile(summaryFilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.Errorf("Error opening summary log %s: %s", summaryFilename, err)
+		return
+	}
+	defer summary.Close()
+	if _, err := summary.WriteString(formatSlowest(timingReport)); err != nil {
+		logger.Errorf("Error writing slowest tests to summary log: %s", err)
+	}
+}
+
+// buildTimingReport collects the durations of the tests from the junit report, and the durations of their stages from
+// the logs that were broken out into the output directory.
+func buildTimingReport(logger *logrus.Logger, outputDir string, report *junitparser.Report, shards reportShards) TimingReport {
+	timingReport := TimingReport{Tests: []TestTiming{}}
+	for i, pkg := range report.Packages {
+		for _, test := range pkg.Tests {
+			timingReport.Tests = append(timingReport.Tests, TestTiming{
+				Name:            test.Name,
+				Package:         pkg.Name,
+				Shard:           shards.name(i),
+				Status:          resultName(test.Result),
+				DurationSeconds: test.Duration.Seconds(),
+				Stages:          parseStageTimings(logger, filepath.Join(outputDir, test.Name+".log")),
+			})
+		}
+	}
+	return timingReport
+}
+
+// parseStageTimings extracts the stages from the given test log. A stage ends when test_structure.RunTestStage logs
+// that it finished. For logs of older versions that do not log this, the stage is assumed to end when the next stage
+// starts, or with the last log line of the test.
+func parseStageTimings(logger *logrus.Logger, filename string) []StageTiming {
+	if !files.FileExists(filename) {
+		return nil
+	}
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		logger.Errorf("Error reading log file %s for timing report: %s", filename, err)
+		return nil
+	}
+
+	stages := []StageTiming{}
+	var openStage *StageTiming
+	var openStageStart, lastTimestamp time.Time
+
+	closeOpenStage := func(end time.Time) {
+		if openStage != nil {
+			openStage.DurationSeconds = end.Sub(openStageStart).Seconds()
+			stages = append(stages, *openStage)
+			openStage = nil
+		}
+	}
+
+	for _, line := range strings.Split(string(contents), "\n") {
+		prefix := regexLogLinePrefix.FindStringSubmatch(line)
+		if prefix == nil {
+			continue
+		}
+		timestamp, err := time.Parse(time.RFC3339, prefix[2])
+		if err != nil {
+			continue
+		}
+		lastTimestamp = timestamp
+
+		switch {
+		case regexStageStart.MatchString(line):
+			closeOpenStage(timestamp)
+			openStage = &StageTiming{Name: regexStageStart.FindStringSubmatch(line)[1], Start: prefix[2]}
+			openStageStart = timestamp
+
+		case regexStageSkip.MatchString(line):
+			closeOpenStage(timestamp)
+			stages = append(stages, StageTiming{Name: regexStageSkip.FindStringSubmatch(line)[1], Skipped: true})
+
+		case regexStageEnd.MatchString(line):
+			match := regexStageEnd.FindStringSubmatch(line)
+			duration, err := time.ParseDuration(match[2])
+			if openStage == nil || openStage.Name != match[1] || err != nil {
+				closeOpenStage(timestamp)
+				continue
+			}
+			openStage.DurationSeconds = duration.Seconds()
+			stages = append(stages, *openStage)
+			openStage = nil
+		}
+	}
+	closeOpenStage(lastTimestamp)
+
+	return stages
+}
+
+// formatSlowest renders the sections of the summary log that list the slowest tests and stages.
+func formatSlowest(timingReport TimingReport) string {
+	tests := append([]TestTiming{}, timingReport.Tests...)
+	sort.SliceStable(tests, func(i, j int) bool { return tests[i].DurationSeconds > tests[j].DurationSeconds })
+
+	type testStage struct {
+		testName string
+		stage    StageTiming
+	}
+	stages := []testStage{}
+	for _, test := range timingReport.Tests {
+		for _, stage := range test.Stages {
+			if !stage.Skipped {
+				stages = append(stages, testStage{testName: timingTestName(test), stage: stage})
+			}
+		}
+	}
+	sort.SliceStable(stages, func(i, j int) bool { return stages[i].stage.DurationSeconds > stages[j].stage.DurationSeconds })
+
+	var builder strings.Builder
+	builder.WriteString("\nSlowest tests:\n")
+	for i := 0; i < len(tests) && i < slowestEntriesInSummary; i++ {
+		fmt.Fprintf(&builder, "%10.2fs  %s\n", tests[i].DurationSeconds, timingTestName(tests[i]))
+	}
+	if len(stages) > 0 {
+		builder.WriteString("\nSlowest stages:\n")
+		for i := 0; i < len(stages) && i < slowestEntriesInSummary; i++ {
+			fmt.Fprintf(&builder, "%10.2fs  %s (stage '%s')\n", stages[i].stage.DurationSeconds, stages[i].testName, stages[i].stage.Name)
+		}
+	}
+	return builder.String()
+}
+
+// timingTestName returns the name of the test to show in the summary, along with the shard it ran in, if any.
+func timingTestName(test TestTiming) string {
+	if test.Shard == "" {
+		return test.Name
+	}
+	return fmt.Sprintf("%s [shard %s]", test.Name, test.Shard)
+}
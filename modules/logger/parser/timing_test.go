@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stagedTestLog = `=== RUN   TestStaged
+TestStaged 2021-01-01T10:00:00Z test_structure.go:25: The 'SKIP_setup' environment variable is set, so skipping stage 'setup'.
+TestStaged 2021-01-01T10:00:00Z test_structure.go:29: The 'SKIP_deploy' environment variable is not set, so executing stage 'deploy'.
+TestStaged 2021-01-01T10:00:30Z command.go:121: Running command terraform with args [apply]
+TestStaged 2021-01-01T10:01:40Z test_structure.go:35: Finished stage 'deploy' in 1m40.5s.
+TestStaged 2021-01-01T10:01:40Z test_structure.go:29: The 'SKIP_validate' environment variable is not set, so executing stage 'validate'.
+TestStaged 2021-01-01T10:01:50Z http_helper.go:32: Making an HTTP GET call
+TestStaged 2021-01-01T10:02:00Z test_structure.go:29: The 'SKIP_teardown' environment variable is not set, so executing stage 'teardown'.
+TestStaged 2021-01-01T10:02:45Z command.go:121: Running command terraform with args [destroy]
+--- PASS: TestStaged (165.00s)
+`
+
+func TestParseStageTimings(t *testing.T) {
+	t.Parallel()
+
+	logger := NewTestLogger(t)
+	filename := filepath.Join(t.TempDir(), "TestStaged.log")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(stagedTestLog), 0644))
+
+	stages := parseStageTimings(logger, filename)
+
+	// The duration of deploy comes from the finished line, while the stages of older versions that don't log it end
+	// when the next stage starts, or with the last log line.
+	assert.Equal(t, []StageTiming{
+		{Name: "setup", Skipped: true},
+		{Name: "deploy", Start: "2021-01-01T10:00:00Z", DurationSeconds: 100.5},
+		{Name: "validate", Start: "2021-01-01T10:01:40Z", DurationSeconds: 20},
+		{Name: "teardown", Start: "2021-01-01T10:02:00Z", DurationSeconds: 45},
+	}, stages)
+}
+
+func TestParseStageTimingsMissingLog(t *testing.T) {
+	t.Parallel()
+
+	logger := NewTestLogger(t)
+	assert.Nil(t, parseStageTimings(logger, filepath.Join(t.TempDir(), "TestMissing.log")))
+}
+
+func TestFormatSlowest(t *testing.T) {
+	t.Parallel()
+
+	summary := formatSlowest(TimingReport{Tests: []TestTiming{
+		{Name: "TestFast", DurationSeconds: 1},
+		{Name: "TestStaged", DurationSeconds: 165, Stages: []StageTiming{
+			{Name: "setup", Skipped: true},
+			{Name: "deploy", DurationSeconds: 100.5},
+			{Name: "teardown", DurationSeconds: 45},
+		}},
+	}})
+
+	assert.Equal(t, `
+Slowest tests:
+    165.00s  TestStaged
+      1.00s  TestFast
+
+Slowest stages:
+    100.50s  TestStaged (stage 'deploy')
+     45.00s  TestStaged (stage 'teardown')
+`, summary)
+}
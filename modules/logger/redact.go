@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// RedactedValue is what sensitive values are replaced with in log lines and error messages.
+	RedactedValue = "***"
+
+	// minSensitiveLength is the minimum length of a value to be redacted. Shorter values (e.g., "1" or "true") are
+	// ignored, as masking every occurrence of them would make the logs unreadable while offering little protection.
+	minSensitiveLength = 4
+)
+
+// Secrets is the global registry of sensitive values. Every log line written by the loggers in this package, and every
+// error message produced by Terratest that may contain command output, is redacted with it.
+var Secrets = NewRedactor()
+
+var (
+	// shortValuesWarnedAbout holds the sensitive values too short to be redacted that a warning was already printed
+	// for, across all the Redactors, so that each of them is only warned about once
+	shortValuesWarnedAbout     = map[string]bool{}
+	shortValuesWarnedAboutLock sync.Mutex
+)
+
+// Redactor keeps track of sensitive values and replaces them with RedactedValue in text. It is safe for concurrent use.
+type Redactor struct {
+	lock   sync.RWMutex
+	values []string
+}
+
+// NewRedactor returns an empty Redactor.
+func NewRedactor() *Redactor {
+	return &Redactor{}
+}
+
+// Add marks the given values as sensitive. Values shorter than 4 characters are not redacted, and a warning is printed
+// to stderr the first time each of them is added.
+func (r *Redactor) Add(values ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, value := range values {
+		if value == "" || r.contains(value) {
+			continue
+		}
+		if len(value) < minSensitiveLength {
+			warnAboutShortValue(value)
+			continue
+		}
+		r.values = append(r.values, value)
+	}
+
+	// Replace the longest values first, so that a value that contains another one is not only partially redacted.
+	sort.SliceStable(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+}
+
+// Redact returns the given text with every sensitive value replaced by RedactedValue.
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, value := range r.values {
+		text = strings.ReplaceAll(text, value, RedactedValue)
+	}
+	return text
+}
+
+// Values returns a copy of the sensitive values known to this Redactor.
+func (r *Redactor) Values() []string {
+	if r == nil {
+		return nil
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return append([]string{}, r.values...)
+}
+
+// warnAboutShortValue prints a warning to stderr that the given sensitive value is too short to be redacted, unless a
+// warning was already printed for it.
+func warnAboutShortValue(value string) {
+	shortValuesWarnedAboutLock.Lock()
+	defer shortValuesWarnedAboutLock.Unlock()
+
+	if shortValuesWarnedAbout[value] {
+		return
+	}
+	shortValuesWarnedAbout[value] = true
+	// Don't log the value itself, as it is sensitive
+	fmt.Fprintf(os.Stderr, "WARNING: a sensitive value of %d characters is not redacted from the logs, as values shorter than %d characters are never redacted\n", len(value), minSensitiveLength)
+}
+
+func (r *Redactor) contains(value string) bool {
+	for _, existing := range r.values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkSensitive marks the given values as sensitive globally, so they are redacted from all log lines and error
+// messages produced by Terratest. Values shorter than 4 characters (e.g., a short PIN) are NOT redacted, as masking
+// every occurrence of them would make the logs unreadable; a warning is printed to stderr once for each of them. For
+// the secrets passed in terraform.Options.Vars, helm SetValues or shell.Command.Env, list their names in
+// terraform.Options.SensitiveVars, helm.Options.SensitiveSetValues or shell.Command.SensitiveEnv instead, so they are
+// marked before each command runs.
+func MarkSensitive(values ...string) {
+	Secrets.Add(values...)
+}
+
+// Redact returns the given text with every globally registered sensitive value replaced by RedactedValue. Use this
+// when building error messages that may contain sensitive values.
+func Redact(text string) string {
+	return Secrets.Redact(text)
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactorRedactsLongestValuesFirst(t *testing.T) {
+	t.Parallel()
+
+	redactor := NewRedactor()
+	redactor.Add("secret", "my-secret-password", "", "abc")
+
+	assert.Equal(t, "password is ***, token is ***, abc is too short", redactor.Redact("password is my-secret-password, token is secret, abc is too short"))
+	assert.Equal(t, []string{"my-secret-password", "secret"}, redactor.Values())
+}
+
+func TestRedactorWarnsAboutShortValues(t *testing.T) {
+	// Not parallel, as this replaces os.Stderr
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	originalStderr := os.Stderr
+	os.Stderr = writer
+	defer func() { os.Stderr = originalStderr }()
+
+	redactor := NewRedactor()
+	redactor.Add("1234", "q7z")
+	redactor.Add("q7z", "1234")
+	NewRedactor().Add("q7z")
+	require.NoError(t, writer.Close())
+	os.Stderr = originalStderr
+
+	warning, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "WARNING: a sensitive value of 3 characters is not redacted from the logs, as values shorter than 4 characters are never redacted\n", string(warning))
+	assert.Equal(t, []string{"1234"}, redactor.Values())
+}
+
+func TestLoggerWithSecrets(t *testing.T) {
+	t.Parallel()
+
+	c := &customLogger{}
+	l := New(c).WithSecrets("hunter2-password")
+	l.Logf(t, "logging in with %s", "hunter2-password")
+	New(c).Logf(t, "logging in with %s", "hunter2-password")
+
+	assert.Equal(t, []string{"logging in with ***", "logging in with hunter2-password"}, c.logs)
+}
+
+func TestDoLogRedactsGlobalSecrets(t *testing.T) {
+	t.Parallel()
+
+	secret := fmt.Sprintf("%s-global-secret", t.Name())
+	MarkSensitive(secret)
+
+	var buffer bytes.Buffer
+	DoLog(t, 1, &buffer, "the secret is", secret)
+
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(buffer.String()), "the secret is ***"))
+	assert.Equal(t, "error: ***", Redact(fmt.Sprintf("error: %s", secret)))
+}
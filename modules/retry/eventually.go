@@ -0,0 +1,210 @@
+package retry
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// Collect is passed to the condition of Eventually and Consistently and collects the assertion failures of a single
+// attempt. It implements the TestingT interface, so it can be used with testify's assert and require packages, as
+// well as with any Terratest function that takes a TestingT. Calling FailNow (e.g. via require, or via a Terratest
+// function that fails the test) stops the current attempt without failing the surrounding test.
+type Collect struct {
+	name   string
+	errors []string
+	failed bool
+}
+
+// Fail marks the current attempt as having failed.
+func (c *Collect) Fail() {
+	c.failed = true
+}
+
+// FailNow marks the current attempt as having failed and stops its execution.
+func (c *Collect) FailNow() {
+	c.Fail()
+	runtime.Goexit()
+}
+
+// Fatal is equivalent to Error followed by FailNow.
+func (c *Collect) Fatal(args ...interface{}) {
+	c.Error(args...)
+	c.FailNow()
+}
+
+// Fatalf is equivalent to Errorf followed by FailNow.
+func (c *Collect) Fatalf(format string, args ...interface{}) {
+	c.Errorf(format, args...)
+	c.FailNow()
+}
+
+// Error records the given arguments as a failure of the current attempt.
+func (c *Collect) Error(args ...interface{}) {
+	c.errors = append(c.errors, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+	c.Fail()
+}
+
+// Errorf records the given format and arguments as a failure of the current attempt.
+func (c *Collect) Errorf(format string, args ...interface{}) {
+	c.errors = append(c.errors, fmt.Sprintf(format, args...))
+	c.Fail()
+}
+
+// Name returns the name of the test the condition is running in.
+func (c *Collect) Name() string {
+	return c.name
+}
+
+// err returns the failures collected during the attempt as a single error, or nil if the attempt succeeded.
+func (c *Collect) err() error {
+	if !c.failed {
+		return nil
+	}
+	if len(c.errors) == 0 {
+		return fmt.Errorf("condition failed without a message")
+	}
+	return fmt.Errorf("%s", strings.Join(c.errors, "\n"))
+}
+
+// Attempt describes a single execution of a retried action or condition.
+type Attempt struct {
+	Number   int
+	Time     time.Time
+	Duration time.Duration
+	Error    error
+}
+
+// runAttempt runs the given condition once, in its own goroutine so that FailNow can stop it, and returns the outcome.
+func runAttempt(t testing.TestingT, number int, condition func(*Collect)) Attempt {
+	collect := &Collect{name: t.Name()}
+	start := time.Now()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		condition(collect)
+	}()
+	<-done
+
+	return Attempt{Number: number, Time: start, Duration: time.Since(start), Error: collect.err()}
+}
+
+// Eventually runs the specified condition every interval until all the assertions in it pass. If the assertions are
+// still failing once the timeout has passed, fail the test with the last failure and the history of attempts.
+func Eventually(t testing.TestingT, timeout time.Duration, interval time.Duration, condition func(c *Collect)) {
+	err := EventuallyE(t, timeout, interval, condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// EventuallyE runs the specified condition every interval until all the assertions in it pass. If the assertions are
+// still failing once the timeout has passed, return a ConditionNotMet error with the history of attempts.
+func EventuallyE(t testing.TestingT, timeout time.Duration, interval time.Duration, condition func(c *Collect)) error {
+	deadline := time.Now().Add(timeout)
+	attempts := []Attempt{}
+
+	for i := 1; ; i++ {
+		attempt := runAttempt(t, i, condition)
+		attempts = append(attempts, attempt)
+		if attempt.Error == nil {
+			return nil
+		}
+
+		if !time.Now().Before(deadline) {
+			return ConditionNotMet{Timeout: timeout, Attempts: attempts}
+		}
+
+		sleep := untilNextAttempt(interval, deadline)
+		logger.Logf(t, "Condition not met on attempt %d: %s. Sleeping for %s and will try again.", i, attempt.Error.Error(), sleep)
+		time.Sleep(sleep)
+	}
+}
+
+// Consistently runs the specified condition every interval for the given duration and fails the test as soon as any
+// of the assertions in it fails, along with the history of attempts.
+func Consistently(t testing.TestingT, duration time.Duration, interval time.Duration, condition func(c *Collect)) {
+	err := ConsistentlyE(t, duration, interval, condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ConsistentlyE runs the specified condition every interval for the given duration. If any of the assertions in it
+// fails, return a ConditionViolated error with the history of attempts immediately.
+func ConsistentlyE(t testing.TestingT, duration time.Duration, interval time.Duration, condition func(c *Collect)) error {
+	deadline := time.Now().Add(duration)
+	attempts := []Attempt{}
+
+	for i := 1; ; i++ {
+		attempt := runAttempt(t, i, condition)
+		attempts = append(attempts, attempt)
+		if attempt.Error != nil {
+			return ConditionViolated{Duration: duration, Attempts: attempts}
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil
+		}
+
+		sleep := untilNextAttempt(interval, deadline)
+		logger.Logf(t, "Condition held on attempt %d. Sleeping for %s before checking again.", i, sleep)
+		time.Sleep(sleep)
+	}
+}
+
+// untilNextAttempt returns how long to sleep before the next attempt: the given interval, or the time left until the
+// deadline if it is shorter, so that the last attempt happens at the deadline rather than one interval before it.
+func untilNextAttempt(interval time.Duration, deadline time.Time) time.Duration {
+	if untilDeadline := time.Until(deadline); untilDeadline < interval {
+		return untilDeadline
+	}
+	return interval
+}
+
+// formatAttempts renders the given attempts as an indented history, one line per attempt.
+func formatAttempts(attempts []Attempt) string {
+	var builder strings.Builder
+	for _, attempt := range attempts {
+		result := "ok"
+		if attempt.Error != nil {
+			result = strings.ReplaceAll(attempt.Error.Error(), "\n", "; ")
+		}
+		fmt.Fprintf(&builder, "\n  #%d at %s (took %s): %s", attempt.Number, attempt.Time.Format(time.RFC3339), attempt.Duration, result)
+	}
+	return builder.String()
+}
+
+// lastError returns the error of the last attempt in the given list, if any.
+func lastError(attempts []Attempt) error {
+	if len(attempts) == 0 {
+		return nil
+	}
+	return attempts[len(attempts)-1].Error
+}
+
+// ConditionNotMet is an error that occurs when the condition passed to Eventually does not pass before the timeout.
+type ConditionNotMet struct {
+	Timeout  time.Duration
+	Attempts []Attempt
+}
+
+func (err ConditionNotMet) Error() string {
+	return logger.Redact(fmt.Sprintf("condition not met within %s after %d attempts, last failure: %v\nattempt history:%s", err.Timeout, len(err.Attempts), lastError(err.Attempts), formatAttempts(err.Attempts)))
+}
+
+// ConditionViolated is an error that occurs when the condition passed to Consistently fails before the duration has
+// passed.
+type ConditionViolated struct {
+	Duration time.Duration
+	Attempts []Attempt
+}
+
+func (err ConditionViolated) Error() string {
+	return logger.Redact(fmt.Sprintf("condition did not hold for %s, failed on attempt %d: %v\nattempt history:%s", err.Duration, len(err.Attempts), lastError(err.Attempts), formatAttempts(err.Attempts)))
+}
@@ -0,0 +1,126 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventually(t *testing.T) {
+	t.Parallel()
+
+	createConditionThatPassesAfterAttempts := func(attempts int) func(c *Collect) {
+		count := 0
+		return func(c *Collect) {
+			count++
+			require.GreaterOrEqual(c, count, attempts)
+			assert.Equal(c, "expected", "expected")
+		}
+	}
+
+	testCases := []struct {
+		description string
+		timeout     time.Duration
+		condition   func(c *Collect)
+		expectError bool
+	}{
+		{"Condition passes on first try", 1 * time.Second, createConditionThatPassesAfterAttempts(1), false},
+		{"Condition passes after 3 attempts", 1 * time.Second, createConditionThatPassesAfterAttempts(3), false},
+		{"Condition never passes", 50 * time.Millisecond, createConditionThatPassesAfterAttempts(1000), true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase // capture range variable for each test case
+
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			err := EventuallyE(t, testCase.timeout, 10*time.Millisecond, testCase.condition)
+			if !testCase.expectError {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			notMet, isNotMet := err.(ConditionNotMet)
+			require.True(t, isNotMet)
+			assert.Equal(t, testCase.timeout, notMet.Timeout)
+			assert.NotEmpty(t, notMet.Attempts)
+			for i, attempt := range notMet.Attempts {
+				assert.Equal(t, i+1, attempt.Number)
+				assert.Error(t, attempt.Error)
+			}
+			assert.Contains(t, err.Error(), "attempt history")
+		})
+	}
+}
+
+func TestEventuallyMakesLastAttemptAtDeadline(t *testing.T) {
+	t.Parallel()
+
+	// The attempts run at 0ms and 80ms, and the last one at the 100ms deadline, rather than stopping at 80ms because
+	// there isn't a full interval left
+	start := time.Now()
+	err := EventuallyE(t, 100*time.Millisecond, 80*time.Millisecond, func(c *Collect) {
+		assert.GreaterOrEqual(c, time.Since(start), 90*time.Millisecond)
+	})
+	assert.NoError(t, err)
+
+	start = time.Now()
+	err = EventuallyE(t, 100*time.Millisecond, 80*time.Millisecond, func(c *Collect) {
+		c.Errorf("never passes")
+	})
+	require.Error(t, err)
+	notMet, isNotMet := err.(ConditionNotMet)
+	require.True(t, isNotMet)
+	require.Len(t, notMet.Attempts, 3)
+	assert.GreaterOrEqual(t, notMet.Attempts[2].Time.Sub(start), 100*time.Millisecond)
+}
+
+func TestConsistently(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Condition always holds", func(t *testing.T) {
+		t.Parallel()
+
+		count := 0
+		err := ConsistentlyE(t, 50*time.Millisecond, 10*time.Millisecond, func(c *Collect) {
+			count++
+			assert.True(c, true)
+		})
+		assert.NoError(t, err)
+		assert.Greater(t, count, 1)
+	})
+
+	t.Run("Condition fails on third attempt", func(t *testing.T) {
+		t.Parallel()
+
+		count := 0
+		err := ConsistentlyE(t, 1*time.Second, 10*time.Millisecond, func(c *Collect) {
+			count++
+			assert.Less(c, count, 3)
+		})
+		require.Error(t, err)
+		violated, isViolated := err.(ConditionViolated)
+		require.True(t, isViolated)
+		require.Len(t, violated.Attempts, 3)
+		assert.NoError(t, violated.Attempts[0].Error)
+		assert.NoError(t, violated.Attempts[1].Error)
+		assert.Error(t, violated.Attempts[2].Error)
+	})
+}
+
+func TestCollectFailNowStopsAttempt(t *testing.T) {
+	t.Parallel()
+
+	reachedEnd := false
+	attempt := runAttempt(t, 1, func(c *Collect) {
+		c.Fatalf("stop here")
+		reachedEnd = true
+	})
+
+	assert.False(t, reachedEnd)
+	assert.EqualError(t, attempt.Error, "stop here")
+}
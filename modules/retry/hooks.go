@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// Hooks holds optional callbacks that are invoked by DoWithRetry and the functions built on top of it. They can be used
+// to emit metrics on which actions are flaky and how long it takes for them to succeed. Any of the callbacks can be
+// left nil.
+type Hooks struct {
+	// OnRetry is called after an attempt fails with a retryable error, before sleeping and trying again.
+	OnRetry func(t testing.TestingT, actionDescription string, attempt Attempt)
+	// OnGiveUp is called when the action fails with a FatalError or when the maximum number of retries is exceeded.
+	OnGiveUp func(t testing.TestingT, actionDescription string, attempts []Attempt)
+	// OnSuccess is called when the action succeeds, with all the attempts it took, including the successful one.
+	OnSuccess func(t testing.TestingT, actionDescription string, attempts []Attempt)
+}
+
+var (
+	hooksLock   sync.RWMutex
+	globalHooks Hooks
+)
+
+// SetHooks replaces the callbacks that are invoked by all the retry functions in this package. This is typically called
+// once from TestMain. Pass an empty Hooks struct to remove them again.
+func SetHooks(hooks Hooks) {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	globalHooks = hooks
+}
+
+func getHooks() Hooks {
+	hooksLock.RLock()
+	defer hooksLock.RUnlock()
+	return globalHooks
+}
+
+func (hooks Hooks) onRetry(t testing.TestingT, actionDescription string, attempt Attempt) {
+	if hooks.OnRetry != nil {
+		hooks.OnRetry(t, actionDescription, attempt)
+	}
+}
+
+func (hooks Hooks) onGiveUp(t testing.TestingT, actionDescription string, attempts []Attempt) {
+	if hooks.OnGiveUp != nil {
+		hooks.OnGiveUp(t, actionDescription, attempts)
+	}
+}
+
+func (hooks Hooks) onSuccess(t testing.TestingT, actionDescription string, attempts []Attempt) {
+	if hooks.OnSuccess != nil {
+		hooks.OnSuccess(t, actionDescription, attempts)
+	}
+}
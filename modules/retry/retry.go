@@ -86,25 +86,40 @@ func DoWithRetryInterface(t testing.TestingT, actionDescription string, maxRetri
 func DoWithRetryInterfaceE(t testing.TestingT, actionDescription string, maxRetries int, sleepBetweenRetries time.Duration, action func() (interface{}, error)) (interface{}, error) {
 	var output interface{}
 	var err error
+	hooks := getHooks()
+	attempts := []Attempt{}
 
 	for i := 0; i <= maxRetries; i++ {
-		logger.Log(t, actionDescription)
+		log := logger.Default.WithFields(logger.Fields{"action": actionDescription, "attempt": i + 1, "max_retries": maxRetries})
+		log.Logf(t, "%s", actionDescription)
 
+		start := time.Now()
 		output, err = action()
+		attempt := Attempt{Number: i + 1, Time: start, Duration: time.Since(start), Error: err}
+		attempts = append(attempts, attempt)
+
 		if err == nil {
+			hooks.onSuccess(t, actionDescription, attempts)
 			return output, nil
 		}
 
 		if _, isFatalErr := err.(FatalError); isFatalErr {
-			logger.Logf(t, "Returning due to fatal error: %v", err)
+			log.Logf(t, "Returning due to fatal error: %v", err)
+			hooks.onGiveUp(t, actionDescription, attempts)
 			return output, err
 		}
 
-		logger.Logf(t, "%s returned an error: %s. Sleeping for %s and will try again.", actionDescription, err.Error(), sleepBetweenRetries)
+		if i == maxRetries {
+			break
+		}
+
+		hooks.onRetry(t, actionDescription, attempt)
+		log.Logf(t, "%s returned an error: %s. Sleeping for %s and will try again.", actionDescription, err.Error(), sleepBetweenRetries)
 		time.Sleep(sleepBetweenRetries)
 	}
 
-	return output, MaxRetriesExceeded{Description: actionDescription, MaxRetries: maxRetries}
+	hooks.onGiveUp(t, actionDescription, attempts)
+	return output, MaxRetriesExceeded{Description: actionDescription, MaxRetries: maxRetries, LastError: err, Attempts: attempts}
 }
 
 // DoWithRetryableErrors runs the specified action. If it returns a value, return that value. If it returns an error,
@@ -195,17 +210,28 @@ type TimeoutExceeded struct {
 }
 
 func (err TimeoutExceeded) Error() string {
-	return fmt.Sprintf("'%s' did not complete before timeout of %s", err.Description, err.Timeout)
+	return logger.Redact(fmt.Sprintf("'%s' did not complete before timeout of %s", err.Description, err.Timeout))
 }
 
-// MaxRetriesExceeded is an error that occurs when the maximum amount of retries is exceeded.
+// MaxRetriesExceeded is an error that occurs when the maximum amount of retries is exceeded. LastError is the error
+// returned by the final attempt and Attempts is the history of all the attempts that were made.
 type MaxRetriesExceeded struct {
 	Description string
 	MaxRetries  int
+	LastError   error
+	Attempts    []Attempt
 }
 
 func (err MaxRetriesExceeded) Error() string {
-	return fmt.Sprintf("'%s' unsuccessful after %d retries", err.Description, err.MaxRetries)
+	if err.LastError == nil {
+		return logger.Redact(fmt.Sprintf("'%s' unsuccessful after %d retries", err.Description, err.MaxRetries))
+	}
+	return logger.Redact(fmt.Sprintf("'%s' unsuccessful after %d retries, last error: %v", err.Description, err.MaxRetries, err.LastError))
+}
+
+// AttemptHistory returns a human readable log of every attempt, with its timestamp, duration and error.
+func (err MaxRetriesExceeded) AttemptHistory() string {
+	return logger.Redact(formatAttempts(err.Attempts))
 }
 
 // FatalError is a marker interface for errors that should not be retried.
@@ -214,5 +240,5 @@ type FatalError struct {
 }
 
 func (err FatalError) Error() string {
-	return fmt.Sprintf("FatalError{Underlying: %v}", err.Underlying)
+	return logger.Redact(fmt.Sprintf("FatalError{Underlying: %v}", err.Underlying))
 }
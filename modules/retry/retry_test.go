@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	terratesting "github.com/gruntwork-io/terratest/modules/testing"
 )
 
 func TestDoWithRetry(t *testing.T) {
@@ -49,7 +51,7 @@ func TestDoWithRetry(t *testing.T) {
 			actualOutput, err := DoWithRetryE(t, testCase.description, testCase.maxRetries, 1*time.Millisecond, testCase.action)
 			assert.Equal(t, expectedOutput, actualOutput)
 			if testCase.expectedError != nil {
-				assert.Equal(t, testCase.expectedError, err)
+				assertRetryError(t, testCase.expectedError, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, expectedOutput, actualOutput)
@@ -247,7 +249,7 @@ func TestDoWithRetryableErrors(t *testing.T) {
 			actualOutput, err := DoWithRetryableErrorsE(t, testCase.description, testCase.retryableErrors, testCase.maxRetries, 1*time.Millisecond, testCase.action)
 			assert.Equal(t, expectedOutput, actualOutput)
 			if testCase.expectedError != nil {
-				assert.Equal(t, testCase.expectedError, err)
+				assertRetryError(t, testCase.expectedError, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, expectedOutput, actualOutput)
@@ -256,8 +258,69 @@ func TestDoWithRetryableErrors(t *testing.T) {
 	}
 }
 
+// assertRetryError checks that actual matches expected. MaxRetriesExceeded errors are compared on their description and
+// number of retries only, as they also carry the timing of each attempt.
+func assertRetryError(t *testing.T, expected error, actual error) {
+	expectedMaxRetriesErr, isMaxRetriesErr := expected.(MaxRetriesExceeded)
+	if !isMaxRetriesErr {
+		assert.Equal(t, expected, actual)
+		return
+	}
+
+	actualMaxRetriesErr, isMaxRetriesErr := actual.(MaxRetriesExceeded)
+	if assert.True(t, isMaxRetriesErr, "expected a MaxRetriesExceeded error, got %v", actual) {
+		assert.Equal(t, expectedMaxRetriesErr.Description, actualMaxRetriesErr.Description)
+		assert.Equal(t, expectedMaxRetriesErr.MaxRetries, actualMaxRetriesErr.MaxRetries)
+		assert.Error(t, actualMaxRetriesErr.LastError)
+		assert.Len(t, actualMaxRetriesErr.Attempts, expectedMaxRetriesErr.MaxRetries+1)
+	}
+}
+
 type ErrorCounter int
 
 func (count ErrorCounter) Error() string {
 	return fmt.Sprintf("%d", int(count))
 }
+
+func TestDoWithRetryHooks(t *testing.T) {
+	// Not parallel, as the hooks are global
+
+	retried := []Attempt{}
+	gaveUp := []Attempt{}
+	succeeded := []Attempt{}
+	SetHooks(Hooks{
+		OnRetry: func(t terratesting.TestingT, actionDescription string, attempt Attempt) {
+			retried = append(retried, attempt)
+		},
+		OnGiveUp: func(t terratesting.TestingT, actionDescription string, attempts []Attempt) {
+			gaveUp = attempts
+		},
+		OnSuccess: func(t terratesting.TestingT, actionDescription string, attempts []Attempt) {
+			succeeded = attempts
+		},
+	})
+	defer SetHooks(Hooks{})
+
+	count := 0
+	_, err := DoWithRetryE(t, "succeed on third attempt", 5, 1*time.Millisecond, func() (string, error) {
+		count++
+		if count < 3 {
+			return "", fmt.Errorf("attempt %d failed", count)
+		}
+		return "done", nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, retried, 2)
+	assert.Len(t, succeeded, 3)
+	assert.Empty(t, gaveUp)
+
+	_, err = DoWithRetryE(t, "always fail", 2, 1*time.Millisecond, func() (string, error) {
+		return "", fmt.Errorf("always fails")
+	})
+	maxRetriesErr, isMaxRetriesErr := err.(MaxRetriesExceeded)
+	assert.True(t, isMaxRetriesErr)
+	assert.Len(t, gaveUp, 3)
+	assert.Equal(t, gaveUp, maxRetriesErr.Attempts)
+	assert.EqualError(t, maxRetriesErr.LastError, "always fails")
+	assert.Contains(t, maxRetriesErr.AttemptHistory(), "#3")
+}
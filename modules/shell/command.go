@@ -22,6 +22,11 @@ type Command struct {
 	Args       []string          // The args to pass to the command
 	WorkingDir string            // The working directory
 	Env        map[string]string // Additional environment variables to set
+	// The names of the Env variables whose values are sensitive. Their values are marked as sensitive in the logger
+	// package before the command runs, so they are redacted from the log lines and error messages.
+	SensitiveEnv []string
+	// The input of the command, e.g. to pass a password with --password-stdin. Defaults to the stdin of this Go program.
+	Stdin io.Reader
 	// Use the specified logger for the command's output. Use logger.Discard to not print the output while executing the command.
 	Logger *logger.Logger
 }
@@ -90,18 +95,28 @@ type ErrWithCmdOutput struct {
 }
 
 func (e *ErrWithCmdOutput) Error() string {
-	return fmt.Sprintf("error while running command: %v; %s", e.Underlying, e.Output.Stderr())
+	return logger.Redact(fmt.Sprintf("error while running command: %v; %s", e.Underlying, e.Output.Stderr()))
 }
 
 // runCommand runs a shell command and stores each line from stdout and stderr in Output. Depending on the logger, the
 // stdout and stderr of that command will also be printed to the stdout and stderr of this Go program to make debugging
 // easier.
 func runCommand(t testing.TestingT, command Command) (*output, error) {
-	command.Logger.Logf(t, "Running command %s with args %s", command.Command, command.Args)
+	for _, name := range command.SensitiveEnv {
+		if value, hasValue := command.Env[name]; hasValue {
+			logger.MarkSensitive(value)
+		}
+	}
+
+	log := command.Logger.WithFields(logger.Fields{"command": command.Command})
+	log.Logf(t, "Running command %s with args %s", command.Command, command.Args)
 
 	cmd := exec.Command(command.Command, command.Args...)
 	cmd.Dir = command.WorkingDir
 	cmd.Stdin = os.Stdin
+	if command.Stdin != nil {
+		cmd.Stdin = command.Stdin
+	}
 	cmd.Env = formatEnvVars(command)
 
 	stdout, err := cmd.StdoutPipe()
@@ -119,7 +134,7 @@ func runCommand(t testing.TestingT, command Command) (*output, error) {
 		return nil, err
 	}
 
-	output, err := readStdoutAndStderr(t, command.Logger, stdout, stderr)
+	output, err := readStdoutAndStderr(t, log, stdout, stderr)
 	if err != nil {
 		return output, err
 	}
@@ -140,11 +155,11 @@ func readStdoutAndStderr(t testing.TestingT, log *logger.Logger, stdout, stderr
 	var stdoutErr, stderrErr error
 	go func() {
 		defer wg.Done()
-		stdoutErr = readData(t, log, stdoutReader, out.stdout)
+		stdoutErr = readData(t, log.WithFields(logger.Fields{"stream": "stdout"}), stdoutReader, out.stdout)
 	}()
 	go func() {
 		defer wg.Done()
-		stderrErr = readData(t, log, stderrReader, out.stderr)
+		stderrErr = readData(t, log.WithFields(logger.Fields{"stream": "stderr"}), stderrReader, out.stderr)
 	}()
 	wg.Wait()
 
@@ -183,3 +183,32 @@ func TestCommandOutputType(t *testing.T) {
 		assert.Len(t, o.Output.Combined(), len(stdout)+len(stderr)+1) // +1 for newline
 	}
 }
+
+func TestRunCommandMarksSensitiveEnv(t *testing.T) {
+	t.Parallel()
+
+	secret := fmt.Sprintf("%s-secret", t.Name())
+	cmd := Command{
+		Command:      "sh",
+		Args:         []string{"-c", "echo $SECRET $PUBLIC"},
+		Env:          map[string]string{"SECRET": secret, "PUBLIC": "public-value"},
+		SensitiveEnv: []string{"SECRET"},
+		Logger:       logger.Discard,
+	}
+
+	out := RunCommandAndGetOutput(t, cmd)
+	assert.Equal(t, fmt.Sprintf("%s public-value", secret), out)
+	assert.Equal(t, "*** public-value", logger.Redact(out))
+}
+
+func TestRunCommandWithStdin(t *testing.T) {
+	t.Parallel()
+
+	cmd := Command{
+		Command: "cat",
+		Stdin:   strings.NewReader("from stdin"),
+		Logger:  logger.Discard,
+	}
+
+	assert.Equal(t, "from stdin", RunCommandAndGetOutput(t, cmd))
+}
@@ -1,9 +1,11 @@
 package terraform
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/gruntwork-io/terratest/modules/collections"
+	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -15,11 +17,21 @@ func generateCommand(options *Options, args ...string) shell.Command {
 		Args:       args,
 		WorkingDir: options.TerraformDir,
 		Env:        options.EnvVars,
-		Logger:     options.Logger,
+		Logger:     terraformLogger(options, args...),
 	}
 	return cmd
 }
 
+// terraformLogger returns the logger of the given options with the terraform subcommand and working dir attached as
+// structured fields.
+func terraformLogger(options *Options, args ...string) *logger.Logger {
+	fields := logger.Fields{"terraform_dir": options.TerraformDir}
+	if len(args) > 0 {
+		fields["terraform_command"] = args[0]
+	}
+	return options.Logger.WithFields(fields)
+}
+
 var commandsWithParallelism = []string{
 	"plan",
 	"apply",
@@ -36,6 +48,8 @@ func GetCommonOptions(options *Options, args ...string) (*Options, []string) {
 		options.TerraformBinary = "terraform"
 	}
 
+	markSensitiveVars(options)
+
 	if options.TerraformBinary == "terragrunt" {
 		args = append(args, "--terragrunt-non-interactive")
 	}
@@ -55,6 +69,22 @@ func GetCommonOptions(options *Options, args ...string) (*Options, []string) {
 	return options, args
 }
 
+// markSensitiveVars marks the values of the Vars listed in SensitiveVars as sensitive in the logger package, including
+// the strings nested in list and map values.
+func markSensitiveVars(options *Options) {
+	for _, name := range options.SensitiveVars {
+		value, hasValue := options.Vars[name]
+		if !hasValue {
+			continue
+		}
+		// Round trip through JSON so that the typed lists and maps (e.g., []string) are handled by collectStrings
+		var decoded interface{}
+		if jsonValue, err := json.Marshal(value); err == nil && json.Unmarshal(jsonValue, &decoded) == nil {
+			logger.MarkSensitive(collectStrings(decoded)...)
+		}
+	}
+}
+
 // RunTerraformCommand runs terraform with the given arguments and options and return stdout/stderr.
 func RunTerraformCommand(t testing.TestingT, additionalOptions *Options, args ...string) string {
 	out, err := RunTerraformCommandE(t, additionalOptions, args...)
@@ -100,7 +130,7 @@ func GetExitCodeForTerraformCommand(t testing.TestingT, additionalOptions *Optio
 func GetExitCodeForTerraformCommandE(t testing.TestingT, additionalOptions *Options, additionalArgs ...string) (int, error) {
 	options, args := GetCommonOptions(additionalOptions, additionalArgs...)
 
-	additionalOptions.Logger.Logf(t, "Running %s with args %v", options.TerraformBinary, args)
+	terraformLogger(options, args...).Logf(t, "Running %s with args %v", options.TerraformBinary, args)
 	cmd := generateCommand(options, args...)
 	_, err := shell.RunCommandAndGetOutputE(t, cmd)
 	if err == nil {
@@ -0,0 +1,29 @@
+package terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+)
+
+func TestGetCommonOptionsMarksSensitiveVars(t *testing.T) {
+	t.Parallel()
+
+	password := fmt.Sprintf("%s-password", t.Name())
+	token := fmt.Sprintf("%s-token", t.Name())
+	public := fmt.Sprintf("%s-public", t.Name())
+	options := &Options{
+		Vars: map[string]interface{}{
+			"password": password,
+			"tokens":   []string{token},
+			"region":   public,
+		},
+		SensitiveVars: []string{"password", "tokens", "missing"},
+	}
+	GetCommonOptions(options, "apply")
+
+	assert.Equal(t, fmt.Sprintf("*** *** %s", public), logger.Redact(fmt.Sprintf("%s %s %s", password, token, public)))
+}
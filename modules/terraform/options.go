@@ -50,6 +50,10 @@ type Options struct {
 	// }
 	Vars map[string]interface{}
 
+	// The names of the Vars whose values are sensitive. Their values are marked as sensitive in the logger package
+	// before each Terraform command runs, so they are redacted from the log lines and error messages.
+	SensitiveVars []string
+
 	VarFiles                 []string               // The var file paths to pass to Terraform commands using -var-file option.
 	Targets                  []string               // The target resources to pass to the terraform command with -target
 	Lock                     bool                   // The lock option to pass to the terraform command with -lock
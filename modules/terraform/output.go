@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
+	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -348,3 +350,70 @@ func OutputAll(t testing.TestingT, options *Options) map[string]interface{} {
 func OutputAllE(t testing.TestingT, options *Options) (map[string]interface{}, error) {
 	return OutputForKeysE(t, options, nil)
 }
+
+// MarkSensitiveOutputs reads all the outputs and marks the values of the ones that are declared as sensitive in
+// Terraform as sensitive in the logger package, so they are redacted from all the log lines and error messages that
+// follow, including the ones logged by Output and OutputJson. This will fail the test if there is an error.
+func MarkSensitiveOutputs(t testing.TestingT, options *Options) {
+	require.NoError(t, MarkSensitiveOutputsE(t, options))
+}
+
+// MarkSensitiveOutputsE reads all the outputs and marks the values of the ones that are declared as sensitive in
+// Terraform as sensitive in the logger package, so they are redacted from all the log lines and error messages that
+// follow, including the ones logged by Output and OutputJson.
+func MarkSensitiveOutputsE(t testing.TestingT, options *Options) error {
+	args := []string{"output", "-no-color", "-json"}
+	binary := options.TerraformBinary
+	if binary == "" {
+		binary = "terraform"
+	}
+
+	// The output has the values of the sensitive outputs, so the command runs with a discarding logger, and the output
+	// is only logged once the values are marked as sensitive, so that they are redacted
+	terraformLogger(options, args...).Logf(t, "Running command %s with args %s", binary, args)
+	quietOptions := *options
+	quietOptions.Logger = logger.Discard
+	out, err := RunTerraformCommandAndGetStdoutE(t, &quietOptions, args...)
+	if err != nil {
+		return err
+	}
+
+	outputMap := map[string]map[string]interface{}{}
+	if err := json.Unmarshal([]byte(out), &outputMap); err != nil {
+		return err
+	}
+
+	for _, output := range outputMap {
+		if sensitive, isBool := output["sensitive"].(bool); isBool && sensitive {
+			logger.MarkSensitive(collectStrings(output["value"])...)
+		}
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		terraformLogger(options, args...).Logf(t, "%s", line)
+	}
+	return nil
+}
+
+// collectStrings returns all the string values in the given decoded JSON value, including the ones nested in lists and
+// maps.
+func collectStrings(value interface{}) []string {
+	switch typed := value.(type) {
+	case string:
+		return []string{typed}
+	case []interface{}:
+		values := []string{}
+		for _, item := range typed {
+			values = append(values, collectStrings(item)...)
+		}
+		return values
+	case map[string]interface{}:
+		values := []string{}
+		for _, item := range typed {
+			values = append(values, collectStrings(item)...)
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -433,3 +433,15 @@ func TestOutputsForKeysError(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+func TestCollectStrings(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]interface{}{
+		"password": "hunter2",
+		"port":     float64(5432),
+		"users":    []interface{}{"alice", map[string]interface{}{"token": "abc123"}},
+	}
+
+	require.ElementsMatch(t, []string{"hunter2", "alice", "abc123"}, collectStrings(value))
+}
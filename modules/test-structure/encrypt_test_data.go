@@ -0,0 +1,109 @@
+package test_structure
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+)
+
+// TEST_DATA_ENCRYPTION_KEY_ENV_VAR is the environment variable that, when set, makes SaveTestData encrypt the test data
+// with a key derived from its value, so that test data with secrets (e.g., the private keys saved with SaveSshKeyPair or
+// the vars saved with SaveTerraformOptions) can be uploaded safely as a CI artifact. LoadTestData decrypts the test
+// data with the same key. Test data saved without encryption can still be loaded when the variable is set.
+const TEST_DATA_ENCRYPTION_KEY_ENV_VAR = "TERRATEST_TEST_DATA_ENCRYPTION_KEY"
+
+// encryptedTestDataPrefix marks the test data encrypted by SaveTestData, followed by the base64 encoded nonce and
+// ciphertext.
+const encryptedTestDataPrefix = "terratest-encrypted:v1:"
+
+// isTestDataEncryptionEnabled returns true if the test data encryption key is set.
+func isTestDataEncryptionEnabled() bool {
+	return os.Getenv(TEST_DATA_ENCRYPTION_KEY_ENV_VAR) != ""
+}
+
+// isEncryptedTestData returns true if the given test data was encrypted by encryptTestData.
+func isEncryptedTestData(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedTestDataPrefix))
+}
+
+// encryptTestData encrypts the given test data with AES-256-GCM, using the SHA-256 hash of the value of the
+// TERRATEST_TEST_DATA_ENCRYPTION_KEY environment variable as the key.
+func encryptTestData(data []byte) ([]byte, error) {
+	gcm, err := newTestDataCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, data, nil)
+
+	return []byte(encryptedTestDataPrefix + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// decryptTestData decrypts the given test data if it was encrypted by encryptTestData, and returns it as is otherwise.
+func decryptTestData(path string, data []byte) ([]byte, error) {
+	if !isEncryptedTestData(data) {
+		return data, nil
+	}
+	if !isTestDataEncryptionEnabled() {
+		return nil, TestDataEncryptionKeyNotSet{Path: path}
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(data[len(encryptedTestDataPrefix):]))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newTestDataCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, TestDataDecryptionFailed{Path: path, Underlying: fmt.Errorf("ciphertext too short")}
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, TestDataDecryptionFailed{Path: path, Underlying: err}
+	}
+	return plaintext, nil
+}
+
+// newTestDataCipher creates the AES-256-GCM cipher for the key in the TERRATEST_TEST_DATA_ENCRYPTION_KEY environment
+// variable.
+func newTestDataCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(os.Getenv(TEST_DATA_ENCRYPTION_KEY_ENV_VAR)))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// TestDataEncryptionKeyNotSet is an error that occurs when loading encrypted test data without the encryption key.
+type TestDataEncryptionKeyNotSet struct {
+	Path string
+}
+
+func (err TestDataEncryptionKeyNotSet) Error() string {
+	return fmt.Sprintf("The test data at %s is encrypted, but the %s environment variable is not set", err.Path, TEST_DATA_ENCRYPTION_KEY_ENV_VAR)
+}
+
+// TestDataDecryptionFailed is an error that occurs when the encrypted test data can't be decrypted, typically because it
+// was encrypted with a different key.
+type TestDataDecryptionFailed struct {
+	Path       string
+	Underlying error
+}
+
+func (err TestDataDecryptionFailed) Error() string {
+	return fmt.Sprintf("Failed to decrypt the test data at %s (was it encrypted with a different %s?): %v", err.Path, TEST_DATA_ENCRYPTION_KEY_ENV_VAR, err.Underlying)
+}
@@ -0,0 +1,470 @@
+package test_structure
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// PipelineStage is a single stage of a Pipeline.
+type PipelineStage struct {
+	// Name of the stage. Same as with RunTestStage, the stage is skipped if the SKIP_<Name> environment variable is set.
+	Name string
+
+	// The names of the stages that must complete successfully before this stage runs.
+	DependsOn []string
+
+	// The keys of the test data that the stage saves, and the keys of the test data that the stage loads. A key is the
+	// name used with SaveString, SaveInt and the like (i.e., the name of the file in the .test-data folder without the
+	// .json extension), so the key of the test data saved with SaveTerraformOptions is "TerraformOptions".
+	Produces []string
+	Consumes []string
+
+	// Teardown stages run after all the other stages, even when they failed, one at a time and in the reverse order of
+	// their declaration, like deferred calls. A teardown stage is skipped when the test data it consumes is not present,
+	// e.g. because the stage that deploys the resources never ran.
+	Teardown bool
+
+	// Run executes the stage. Since stages may run in parallel, Run must use the given TestingT, rather than the
+	// TestingT of the test, to fail the stage.
+	Run func(t testing.TestingT)
+}
+
+// Pipeline is a set of test stages with dependencies between them. Unlike a sequence of RunTestStage calls, stages that
+// don't depend on each other run in parallel, a stage whose dependency failed does not run, and the test data that a
+// skipped stage should have produced is checked before anything runs.
+type Pipeline struct {
+	// The folder in which the test data of the stages is saved, as passed to SaveTestData and friends.
+	TestFolder string
+	Stages     []PipelineStage
+	// The maximum number of stages to run at the same time. Defaults to no limit.
+	MaxParallel int
+}
+
+// stageStatus is the state of a stage while the pipeline runs.
+type stageStatus int
+
+const (
+	stagePending stageStatus = iota
+	stageRunning
+	stageSucceeded
+	stageFailed
+	stageBlocked
+)
+
+// stageT is the TestingT passed to the stages of a pipeline. It records the failures of the stage instead of failing the
+// test right away, as FailNow may only be called from the goroutine running the test.
+type stageT struct {
+	name   string
+	lock   sync.Mutex
+	errors []string
+	failed bool
+}
+
+// Fail marks the stage as having failed.
+func (t *stageT) Fail() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.failed = true
+}
+
+// FailNow marks the stage as having failed and stops its execution.
+func (t *stageT) FailNow() {
+	t.Fail()
+	runtime.Goexit()
+}
+
+// Fatal is equivalent to Error followed by FailNow.
+func (t *stageT) Fatal(args ...interface{}) {
+	t.Error(args...)
+	t.FailNow()
+}
+
+// Fatalf is equivalent to Errorf followed by FailNow.
+func (t *stageT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	t.FailNow()
+}
+
+// Error records the given arguments as a failure of the stage.
+func (t *stageT) Error(args ...interface{}) {
+	t.Errorf("%s", strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+// Errorf records the given format and arguments as a failure of the stage.
+func (t *stageT) Errorf(format string, args ...interface{}) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+	t.failed = true
+}
+
+// Name returns the name of the test the pipeline is running in.
+func (t *stageT) Name() string {
+	return t.name
+}
+
+// Failed returns true if the stage failed.
+func (t *stageT) Failed() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.failed
+}
+
+// err returns the failures of the stage as a single error, or nil if the stage succeeded.
+func (t *stageT) err() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.failed {
+		return nil
+	}
+	if len(t.errors) == 0 {
+		return fmt.Errorf("stage failed without a message")
+	}
+	return fmt.Errorf("%s", strings.Join(t.errors, "\n"))
+}
+
+// Validate checks that the stages of the pipeline are consistent: the names are unique, the dependencies exist and have
+// no cycles, the test data consumed by each stage is produced by one of its dependencies (or is already present), and
+// the test data that skipped stages should have produced is present. If there are any errors, fail the test.
+func (pipeline *Pipeline) Validate(t testing.TestingT) {
+	require.NoError(t, pipeline.ValidateE(t))
+}
+
+// ValidateE checks that the stages of the pipeline are consistent: the names are unique, the dependencies exist and have
+// no cycles, the test data consumed by each stage is produced by one of its dependencies (or is already present), and
+// the test data that skipped stages should have produced is present.
+func (pipeline *Pipeline) ValidateE(t testing.TestingT) error {
+	problems := []string{}
+	stages := map[string]PipelineStage{}
+	for _, stage := range pipeline.Stages {
+		if _, exists := stages[stage.Name]; exists {
+			problems = append(problems, fmt.Sprintf("stage '%s' is declared more than once", stage.Name))
+		}
+		if stage.Run == nil {
+			problems = append(problems, fmt.Sprintf("stage '%s' has no Run function", stage.Name))
+		}
+		stages[stage.Name] = stage
+	}
+
+	for _, stage := range pipeline.Stages {
+		if stage.Teardown && len(stage.DependsOn) > 0 {
+			problems = append(problems, fmt.Sprintf("teardown stage '%s' declares dependencies, but teardown stages run in the reverse order of their declaration", stage.Name))
+			continue
+		}
+		for _, dependency := range stage.DependsOn {
+			dependencyStage, exists := stages[dependency]
+			switch {
+			case !exists:
+				problems = append(problems, fmt.Sprintf("stage '%s' depends on unknown stage '%s'", stage.Name, dependency))
+			case dependencyStage.Teardown:
+				problems = append(problems, fmt.Sprintf("stage '%s' depends on teardown stage '%s', which runs after it", stage.Name, dependency))
+			}
+		}
+	}
+	if cycle := findDependencyCycle(pipeline.Stages, stages); cycle != nil {
+		problems = append(problems, fmt.Sprintf("stages have a dependency cycle: %s", strings.Join(cycle, " -> ")))
+	}
+	if len(problems) > 0 {
+		return InvalidPipeline{Problems: problems}
+	}
+
+	producers := map[string][]string{}
+	for _, stage := range pipeline.Stages {
+		for _, key := range stage.Produces {
+			producers[key] = append(producers[key], stage.Name)
+		}
+	}
+
+	for _, stage := range pipeline.Stages {
+		if stage.Teardown {
+			// Teardown stages run after all the other stages, and are skipped when their inputs are not present.
+			continue
+		}
+		ancestors := dependencyClosure(stage.Name, stages)
+		for _, key := range stage.Consumes {
+			producedByAncestor := false
+			for _, producer := range producers[key] {
+				producedByAncestor = producedByAncestor || ancestors[producer]
+			}
+			switch {
+			case producedByAncestor:
+			case len(producers[key]) > 0:
+				problems = append(problems, fmt.Sprintf("stage '%s' consumes '%s', which is produced by %s, but does not depend on it", stage.Name, key, quoteStageNames(producers[key])))
+			case !IsTestDataPresent(t, formatNamedTestDataPath(pipeline.TestFolder, key)):
+				problems = append(problems, fmt.Sprintf("stage '%s' consumes '%s', which no stage produces and is not present in %s", stage.Name, key, pipeline.TestFolder))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return InvalidPipeline{Problems: problems}
+	}
+
+	// The outputs of skipped stages must have been saved by a previous run, as the stages that consume them would fail
+	// half way otherwise.
+	for _, stage := range pipeline.Stages {
+		if stage.Teardown || !isStageSkipped(stage.Name) {
+			continue
+		}
+		missing := pipeline.missingTestData(t, stage.Produces)
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("stage '%s' is skipped, but the test data it produces is not present: %s", stage.Name, strings.Join(missing, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return InvalidPipeline{Problems: problems}
+	}
+	return nil
+}
+
+// Run validates the pipeline and executes its stages. Stages run as soon as all their dependencies completed
+// successfully, in parallel with the other stages that are ready, and a stage whose dependency failed does not run. The
+// teardown stages run once all the other stages are done, even when some of them failed. If any stage fails, fail the
+// test once the teardown stages are done.
+func (pipeline *Pipeline) Run(t testing.TestingT) {
+	require.NoError(t, pipeline.RunE(t))
+}
+
+// RunE validates the pipeline and executes its stages. Stages run as soon as all their dependencies completed
+// successfully, in parallel with the other stages that are ready, and a stage whose dependency failed does not run. The
+// teardown stages run once all the other stages are done, even when some of them failed. Returns an error listing the
+// failures of all the stages that failed.
+func (pipeline *Pipeline) RunE(t testing.TestingT) error {
+	if err := pipeline.ValidateE(t); err != nil {
+		return err
+	}
+
+	failures := map[string]error{}
+	statuses := map[string]stageStatus{}
+	stages := []PipelineStage{}
+	teardownStages := []PipelineStage{}
+	for _, stage := range pipeline.Stages {
+		if stage.Teardown {
+			teardownStages = append(teardownStages, stage)
+		} else {
+			stages = append(stages, stage)
+		}
+		statuses[stage.Name] = stagePending
+	}
+
+	type stageResult struct {
+		name string
+		err  error
+	}
+	results := make(chan stageResult)
+	running := 0
+
+	for {
+		// Blocking a stage may block the stages that depend on it, so keep going until no more stages get blocked.
+		for blockedAny := true; blockedAny; {
+			blockedAny = false
+			for _, stage := range stages {
+				if statuses[stage.Name] != stagePending {
+					continue
+				}
+				for _, dependency := range stage.DependsOn {
+					if statuses[dependency] == stageFailed || statuses[dependency] == stageBlocked {
+						logger.Logf(t, "Stage '%s' did not complete, so not running stage '%s'.", dependency, stage.Name)
+						statuses[stage.Name] = stageBlocked
+						blockedAny = true
+						break
+					}
+				}
+			}
+		}
+
+		for _, stage := range stages {
+			if pipeline.MaxParallel > 0 && running >= pipeline.MaxParallel {
+				break
+			}
+			if statuses[stage.Name] != stagePending || !allStagesSucceeded(stage.DependsOn, statuses) {
+				continue
+			}
+
+			statuses[stage.Name] = stageRunning
+			running++
+			go func(stage PipelineStage) {
+				results <- stageResult{name: stage.Name, err: pipeline.runStage(t, stage)}
+			}(stage)
+		}
+
+		if running == 0 {
+			break
+		}
+		result := <-results
+		running--
+		if result.err != nil {
+			statuses[result.name] = stageFailed
+			failures[result.name] = result.err
+		} else {
+			statuses[result.name] = stageSucceeded
+		}
+	}
+
+	for i := len(teardownStages) - 1; i >= 0; i-- {
+		stage := teardownStages[i]
+		missing := pipeline.missingTestData(t, stage.Consumes)
+		if len(missing) > 0 {
+			logger.Logf(t, "The test data consumed by teardown stage '%s' is not present (%s), so skipping stage '%s'.", stage.Name, strings.Join(missing, ", "), stage.Name)
+			continue
+		}
+		if err := pipeline.runStage(t, stage); err != nil {
+			failures[stage.Name] = err
+		}
+	}
+
+	if len(failures) > 0 {
+		return PipelineFailed{Failures: failures}
+	}
+	return nil
+}
+
+// runStage executes a single stage with RunTestStage, so that it can be skipped with the SKIP_<name> environment
+// variable, and returns the failures of the stage. Panics in the stage are turned into failures, as they would crash
+// the test binary when the stage runs in its own goroutine.
+func (pipeline *Pipeline) runStage(t testing.TestingT, stage PipelineStage) error {
+	stageT := &stageT{name: t.Name()}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				stageT.Errorf("stage panicked: %v", recovered)
+			}
+		}()
+		RunTestStage(stageT, stage.Name, func() { stage.Run(stageT) })
+	}()
+	<-done
+	return stageT.err()
+}
+
+// allStagesSucceeded returns true if all the given stages completed successfully.
+func allStagesSucceeded(names []string, statuses map[string]stageStatus) bool {
+	for _, name := range names {
+		if statuses[name] != stageSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// missingTestData returns the keys of the given test data that is not present in the test folder.
+func (pipeline *Pipeline) missingTestData(t testing.TestingT, keys []string) []string {
+	missing := []string{}
+	for _, key := range keys {
+		if !IsTestDataPresent(t, formatNamedTestDataPath(pipeline.TestFolder, key)) {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// isStageSkipped returns true if the SKIP_<stageName> environment variable is set.
+func isStageSkipped(stageName string) bool {
+	return os.Getenv(fmt.Sprintf("%s%s", SKIP_STAGE_ENV_VAR_PREFIX, stageName)) != ""
+}
+
+// dependencyClosure returns the names of all the stages the given stage depends on, directly or transitively.
+func dependencyClosure(stageName string, stages map[string]PipelineStage) map[string]bool {
+	closure := map[string]bool{}
+	toVisit := append([]string{}, stages[stageName].DependsOn...)
+	for len(toVisit) > 0 {
+		name := toVisit[0]
+		toVisit = toVisit[1:]
+		if closure[name] {
+			continue
+		}
+		closure[name] = true
+		toVisit = append(toVisit, stages[name].DependsOn...)
+	}
+	return closure
+}
+
+// findDependencyCycle returns the names of the stages in a dependency cycle, or nil if there is none.
+func findDependencyCycle(declared []PipelineStage, stages map[string]PipelineStage) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	path := []string{}
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			for i, pathName := range path {
+				if pathName == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range stages[name].DependsOn {
+			if _, exists := stages[dependency]; !exists {
+				continue
+			}
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, stage := range declared {
+		if cycle := visit(stage.Name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// quoteStageNames formats the given stage names for an error message.
+func quoteStageNames(names []string) string {
+	quoted := []string{}
+	for _, name := range names {
+		quoted = append(quoted, fmt.Sprintf("'%s'", name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// InvalidPipeline is an error that occurs when the stages of a pipeline are not consistent.
+type InvalidPipeline struct {
+	Problems []string
+}
+
+func (err InvalidPipeline) Error() string {
+	return fmt.Sprintf("Invalid pipeline:\n  %s", strings.Join(err.Problems, "\n  "))
+}
+
+// PipelineFailed is an error that occurs when one or more stages of a pipeline fail.
+type PipelineFailed struct {
+	Failures map[string]error
+}
+
+func (err PipelineFailed) Error() string {
+	names := []string{}
+	for name := range err.Failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := []string{}
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("stage '%s' failed: %v", name, err.Failures[name]))
+	}
+	return fmt.Sprintf("%d stage(s) failed:\n%s", len(names), strings.Join(messages, "\n"))
+}
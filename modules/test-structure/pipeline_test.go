@@ -0,0 +1,188 @@
+package test_structure
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	terratesting "github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stageRecorder records the order in which the stages of a pipeline ran.
+type stageRecorder struct {
+	lock  sync.Mutex
+	order []string
+}
+
+func (recorder *stageRecorder) record(name string) func(t terratesting.TestingT) {
+	return func(t terratesting.TestingT) {
+		recorder.lock.Lock()
+		defer recorder.lock.Unlock()
+		recorder.order = append(recorder.order, name)
+	}
+}
+
+func TestPipelineRunsStagesInDependencyOrder(t *testing.T) {
+	t.Parallel()
+
+	testFolder := t.TempDir()
+	recorder := &stageRecorder{}
+
+	// The network and the database are independent, so they run at the same time: each waits for the other to start.
+	networkStarted := make(chan struct{})
+	databaseStarted := make(chan struct{})
+	waitFor := func(name string, started chan struct{}, other chan struct{}) func(t terratesting.TestingT) {
+		return func(t terratesting.TestingT) {
+			close(started)
+			select {
+			case <-other:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("stage %s did not run in parallel", name)
+			}
+			SaveString(t, testFolder, name, name+"-id")
+			recorder.record(name)(t)
+		}
+	}
+
+	pipeline := &Pipeline{
+		TestFolder: testFolder,
+		Stages: []PipelineStage{
+			{Name: "teardown_database", Teardown: true, Consumes: []string{"database"}, Run: recorder.record("teardown_database")},
+			{Name: "teardown_network", Teardown: true, Consumes: []string{"network"}, Run: recorder.record("teardown_network")},
+			{Name: "network", Produces: []string{"network"}, Run: waitFor("network", networkStarted, databaseStarted)},
+			{Name: "database", Produces: []string{"database"}, Run: waitFor("database", databaseStarted, networkStarted)},
+			{
+				Name:      "app",
+				DependsOn: []string{"network", "database"},
+				Consumes:  []string{"network", "database"},
+				Run: func(t terratesting.TestingT) {
+					assert.Equal(t, "network-id", LoadString(t, testFolder, "network"))
+					recorder.record("app")(t)
+				},
+			},
+		},
+	}
+	pipeline.Run(t)
+
+	require.Len(t, recorder.order, 5)
+	assert.ElementsMatch(t, []string{"network", "database"}, recorder.order[:2])
+	assert.Equal(t, []string{"app", "teardown_network", "teardown_database"}, recorder.order[2:])
+}
+
+func TestPipelineSkipsDependentsOfFailedStageAndRunsTeardown(t *testing.T) {
+	t.Parallel()
+
+	testFolder := t.TempDir()
+	recorder := &stageRecorder{}
+
+	pipeline := &Pipeline{
+		TestFolder: testFolder,
+		Stages: []PipelineStage{
+			{Name: "teardown", Teardown: true, Run: recorder.record("teardown")},
+			{Name: "deploy", Run: func(t terratesting.TestingT) {
+				recorder.record("deploy")(t)
+				require.Fail(t, "deploy failed")
+			}},
+			{Name: "validate", DependsOn: []string{"deploy"}, Run: recorder.record("validate")},
+			{Name: "validate_more", DependsOn: []string{"validate"}, Run: recorder.record("validate_more")},
+			{Name: "lint", Run: func(t terratesting.TestingT) {
+				recorder.record("lint")(t)
+				panic("lint panicked")
+			}},
+		},
+	}
+	err := pipeline.RunE(t)
+
+	require.Error(t, err)
+	failed, ok := err.(PipelineFailed)
+	require.True(t, ok)
+	assert.Len(t, failed.Failures, 2)
+	assert.Contains(t, failed.Failures["deploy"].Error(), "deploy failed")
+	assert.Contains(t, failed.Failures["lint"].Error(), "lint panicked")
+	assert.ElementsMatch(t, []string{"deploy", "lint", "teardown"}, recorder.order)
+	assert.Equal(t, "teardown", recorder.order[2])
+}
+
+func TestPipelineValidate(t *testing.T) {
+	t.Parallel()
+
+	noop := func(t terratesting.TestingT) {}
+	testCases := []struct {
+		name     string
+		stages   []PipelineStage
+		expected string
+	}{
+		{
+			"unknown dependency",
+			[]PipelineStage{{Name: "validate", DependsOn: []string{"deploy"}, Run: noop}},
+			"stage 'validate' depends on unknown stage 'deploy'",
+		},
+		{
+			"cycle",
+			[]PipelineStage{
+				{Name: "a", DependsOn: []string{"c"}, Run: noop},
+				{Name: "b", DependsOn: []string{"a"}, Run: noop},
+				{Name: "c", DependsOn: []string{"b"}, Run: noop},
+			},
+			"stages have a dependency cycle: a -> c -> b -> a",
+		},
+		{
+			"consumes without depending on producer",
+			[]PipelineStage{
+				{Name: "deploy", Produces: []string{"TerraformOptions"}, Run: noop},
+				{Name: "validate", Consumes: []string{"TerraformOptions"}, Run: noop},
+			},
+			"stage 'validate' consumes 'TerraformOptions', which is produced by 'deploy', but does not depend on it",
+		},
+		{
+			"consumes missing test data",
+			[]PipelineStage{{Name: "validate", Consumes: []string{"TerraformOptions"}, Run: noop}},
+			"stage 'validate' consumes 'TerraformOptions', which no stage produces and is not present",
+		},
+		{
+			"teardown with dependencies",
+			[]PipelineStage{
+				{Name: "deploy", Run: noop},
+				{Name: "teardown", Teardown: true, DependsOn: []string{"deploy"}, Run: noop},
+			},
+			"teardown stage 'teardown' declares dependencies",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			pipeline := &Pipeline{TestFolder: t.TempDir(), Stages: testCase.stages}
+			err := pipeline.ValidateE(t)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expected)
+		})
+	}
+}
+
+func TestPipelineValidatesOutputsOfSkippedStages(t *testing.T) {
+	testFolder := t.TempDir()
+	t.Setenv(SKIP_STAGE_ENV_VAR_PREFIX+"deploy", "true")
+	ran := false
+
+	pipeline := &Pipeline{
+		TestFolder: testFolder,
+		Stages: []PipelineStage{
+			{Name: "deploy", Produces: []string{"TerraformOptions"}, Run: func(t terratesting.TestingT) {}},
+			{Name: "validate", DependsOn: []string{"deploy"}, Consumes: []string{"TerraformOptions"}, Run: func(t terratesting.TestingT) { ran = true }},
+		},
+	}
+
+	err := pipeline.RunE(t)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stage 'deploy' is skipped, but the test data it produces is not present: TerraformOptions")
+	assert.False(t, ran)
+
+	// Once a previous run saved the outputs of the skipped stage, the stages that consume them run
+	SaveString(t, testFolder, "TerraformOptions", "saved")
+	pipeline.Run(t)
+	assert.True(t, ran)
+}
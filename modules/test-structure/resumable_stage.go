@@ -0,0 +1,100 @@
+package test_structure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// RESET_STAGES_ENV_VAR is the environment variable that, when set, makes RunResumableTestStage ignore the stages that
+// completed in a previous run, so that all the stages run again.
+const RESET_STAGES_ENV_VAR = "TERRATEST_RESET_STAGES"
+
+// stagesTestDataFolder is the folder inside the .test-data folder where the completed stages are recorded.
+const stagesTestDataFolder = "stages"
+
+// StageStatus is the record of a stage that completed successfully, stored in the test data folder.
+type StageStatus struct {
+	Stage       string
+	CompletedAt time.Time
+}
+
+// failedReporter is implemented by the TestingT implementations that can tell if the test failed, such as testing.T.
+// This is used to avoid recording stages that failed with a non fatal error (e.g., t.Errorf) as completed.
+type failedReporter interface {
+	Failed() bool
+}
+
+// RunResumableTestStage executes the given test stage, same as RunTestStage, and records in the test data folder inside
+// testFolder that the stage completed successfully. When the test is run again with the same testFolder, the stages that
+// completed in a previous run are skipped, so that the test resumes at the first stage that failed. Set the
+// TERRATEST_RESET_STAGES environment variable, or call ResetTestStages, to run all the stages again. The SKIP_<stageName>
+// environment variables still take precedence.
+func RunResumableTestStage(t testing.TestingT, testFolder string, stageName string, stage func()) {
+	envVarName := fmt.Sprintf("%s%s", SKIP_STAGE_ENV_VAR_PREFIX, stageName)
+	if os.Getenv(envVarName) != "" {
+		logger.Logf(t, "The '%s' environment variable is set, so skipping stage '%s'.", envVarName, stageName)
+		return
+	}
+
+	path := formatStageStatusPath(testFolder, stageName)
+	if os.Getenv(RESET_STAGES_ENV_VAR) == "" && IsTestDataPresent(t, path) {
+		logger.Logf(t, "Stage '%s' completed in a previous run (recorded in %s), so skipping stage '%s'.", stageName, path, stageName)
+		return
+	}
+
+	RunTestStage(t, stageName, stage)
+
+	// A stage that fails the test with t.FailNow (e.g., through require) never returns, so only stages that succeeded get
+	// here, unless they failed with a non fatal error.
+	if reporter, ok := t.(failedReporter); ok && reporter.Failed() {
+		return
+	}
+	SaveTestData(t, path, StageStatus{Stage: stageName, CompletedAt: time.Now()})
+}
+
+// RunResumableTeardownStage executes the given teardown stage, unless the SKIP_<stageName> environment variable is set.
+// Unlike the stages run with RunResumableTestStage, a teardown stage always runs, even when it completed in a previous
+// run. The recorded stages are reset before the teardown starts, as the resources they created are (at least partly)
+// gone afterwards, so the next run starts from the first stage again. To resume a failed test, set SKIP_<stageName> so
+// that the teardown stage does not run.
+func RunResumableTeardownStage(t testing.TestingT, testFolder string, stageName string, stage func()) {
+	envVarName := fmt.Sprintf("%s%s", SKIP_STAGE_ENV_VAR_PREFIX, stageName)
+	if os.Getenv(envVarName) != "" {
+		logger.Logf(t, "The '%s' environment variable is set, so skipping stage '%s'.", envVarName, stageName)
+		return
+	}
+
+	ResetTestStages(t, testFolder)
+	RunTestStage(t, stageName, stage)
+}
+
+// IsTestStageCompleted returns true if the given stage was recorded as completed by RunResumableTestStage in the test
+// data folder inside testFolder.
+func IsTestStageCompleted(t testing.TestingT, testFolder string, stageName string) bool {
+	return IsTestDataPresent(t, formatStageStatusPath(testFolder, stageName))
+}
+
+// ResetTestStages removes the records of the stages that completed in previous runs from the test data folder inside
+// testFolder, so that all the stages run again. If there are any errors, fail the test.
+func ResetTestStages(t testing.TestingT, testFolder string) {
+	require.NoError(t, ResetTestStagesE(t, testFolder))
+}
+
+// ResetTestStagesE removes the records of the stages that completed in previous runs from the test data folder inside
+// testFolder, so that all the stages run again.
+func ResetTestStagesE(t testing.TestingT, testFolder string) error {
+	path := FormatTestDataPath(testFolder, stagesTestDataFolder)
+	logger.Logf(t, "Resetting the completed test stages in %s", path)
+	return DefaultTestDataStore.DeleteAll(t, path)
+}
+
+// formatStageStatusPath formats a path to record that the given stage completed in the given folder.
+func formatStageStatusPath(testFolder string, stageName string) string {
+	return FormatTestDataPath(testFolder, filepath.Join(stagesTestDataFolder, fmt.Sprintf("%s.json", stageName)))
+}
@@ -0,0 +1,87 @@
+package test_structure
+
+import (
+	"runtime"
+	"testing"
+
+	terratesting "github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingT records failures instead of failing the actual test, and stops the goroutine on FailNow like testing.T.
+type failingT struct {
+	failed bool
+}
+
+func (t *failingT) Fail()                                     { t.failed = true }
+func (t *failingT) FailNow()                                  { t.failed = true; runtime.Goexit() }
+func (t *failingT) Fatal(args ...interface{})                 { t.FailNow() }
+func (t *failingT) Fatalf(format string, args ...interface{}) { t.FailNow() }
+func (t *failingT) Error(args ...interface{})                 { t.Fail() }
+func (t *failingT) Errorf(format string, args ...interface{}) { t.Fail() }
+func (t *failingT) Name() string                              { return "failingT" }
+func (t *failingT) Failed() bool                              { return t.failed }
+
+func TestRunResumableTestStageResumesAtFailedStage(t *testing.T) {
+	testFolder := t.TempDir()
+	runs := map[string]int{}
+
+	runStages := func(t terratesting.TestingT, failValidate bool) {
+		RunResumableTestStage(t, testFolder, "deploy", func() { runs["deploy"]++ })
+		RunResumableTestStage(t, testFolder, "validate", func() {
+			runs["validate"]++
+			if failValidate {
+				t.Fatal("validation failed")
+			}
+		})
+	}
+
+	// Run the stages with a fake TestingT, so that the failure of the validate stage doesn't fail this test.
+	failing := &failingT{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runStages(failing, true)
+	}()
+	<-done
+
+	assert.True(t, failing.failed)
+	assert.Equal(t, map[string]int{"deploy": 1, "validate": 1}, runs)
+	assert.True(t, IsTestStageCompleted(t, testFolder, "deploy"))
+	assert.False(t, IsTestStageCompleted(t, testFolder, "validate"))
+
+	// The rerun skips the deploy stage that completed before, and resumes at the validate stage
+	runStages(t, false)
+	assert.Equal(t, map[string]int{"deploy": 1, "validate": 2}, runs)
+	assert.True(t, IsTestStageCompleted(t, testFolder, "validate"))
+
+	RunResumableTeardownStage(t, testFolder, "teardown", func() { runs["teardown"]++ })
+	assert.Equal(t, 1, runs["teardown"])
+	assert.False(t, IsTestStageCompleted(t, testFolder, "deploy"))
+
+	// After the teardown, all the stages run again
+	runStages(t, false)
+	assert.Equal(t, map[string]int{"deploy": 2, "validate": 3, "teardown": 1}, runs)
+}
+
+func TestRunResumableTestStageResetEnvVar(t *testing.T) {
+	testFolder := t.TempDir()
+	runs := 0
+
+	RunResumableTestStage(t, testFolder, "deploy", func() { runs++ })
+	t.Setenv(RESET_STAGES_ENV_VAR, "true")
+	RunResumableTestStage(t, testFolder, "deploy", func() { runs++ })
+	assert.Equal(t, 2, runs)
+}
+
+func TestRunResumableTestStageSkipEnvVar(t *testing.T) {
+	testFolder := t.TempDir()
+	t.Setenv(SKIP_STAGE_ENV_VAR_PREFIX+"deploy", "true")
+	t.Setenv(SKIP_STAGE_ENV_VAR_PREFIX+"teardown", "true")
+	runs := 0
+
+	RunResumableTestStage(t, testFolder, "deploy", func() { runs++ })
+	RunResumableTeardownStage(t, testFolder, "teardown", func() { runs++ })
+	assert.Equal(t, 0, runs)
+	assert.False(t, IsTestStageCompleted(t, testFolder, "deploy"))
+}
@@ -3,12 +3,9 @@ package test_structure
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"path/filepath"
 
 	"github.com/gruntwork-io/terratest/modules/aws"
-	"github.com/gruntwork-io/terratest/modules/files"
 	"github.com/gruntwork-io/terratest/modules/k8s"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/packer"
@@ -183,12 +180,19 @@ func FormatTestDataPath(testFolder string, filename string) string {
 }
 
 // SaveTestData serializes and saves a value used at test time to the given path. This allows you to create some sort of test data
-// (e.g., TerraformOptions) during setup and to reuse this data later during validation and teardown.
+// (e.g., TerraformOptions) during setup and to reuse this data later during validation and teardown. If the
+// TERRATEST_TEST_DATA_ENCRYPTION_KEY environment variable is set, the test data is encrypted with that key.
 func SaveTestData(t testing.TestingT, path string, value interface{}) {
 	logger.Logf(t, "Storing test data in %s so it can be reused later", path)
 
+	encrypt := isTestDataEncryptionEnabled()
+
 	if IsTestDataPresent(t, path) {
-		logger.Logf(t, "[WARNING] The named test data at path %s is non-empty. Save operation will overwrite existing value with \"%v\".\n.", path, value)
+		if encrypt {
+			logger.Logf(t, "[WARNING] The named test data at path %s is non-empty. Save operation will overwrite existing value.", path)
+		} else {
+			logger.Logf(t, "[WARNING] The named test data at path %s is non-empty. Save operation will overwrite existing value with \"%v\".\n.", path, value)
+		}
 	}
 
 	bytes, err := json.Marshal(value)
@@ -196,25 +200,34 @@ func SaveTestData(t testing.TestingT, path string, value interface{}) {
 		t.Fatalf("Failed to convert value %s to JSON: %v", path, err)
 	}
 
-	logger.Logf(t, "Marshalled JSON: %s", string(bytes))
-
-	parentDir := filepath.Dir(path)
-	if err := os.MkdirAll(parentDir, 0777); err != nil {
-		t.Fatalf("Failed to create folder %s: %v", parentDir, err)
+	// Don't log the encrypted test data, as the point of encrypting it is to keep its secrets out of the CI artifacts
+	if encrypt {
+		logger.Logf(t, "Encrypting test data with the key in the %s environment variable", TEST_DATA_ENCRYPTION_KEY_ENV_VAR)
+		bytes, err = encryptTestData(bytes)
+		if err != nil {
+			t.Fatalf("Failed to encrypt value %s: %v", path, err)
+		}
+	} else {
+		logger.Logf(t, "Marshalled JSON: %s", string(bytes))
 	}
 
-	if err := ioutil.WriteFile(path, bytes, 0644); err != nil {
+	if err := DefaultTestDataStore.Save(t, path, bytes); err != nil {
 		t.Fatalf("Failed to save value %s: %v", path, err)
 	}
 }
 
 // LoadTestData loads and unserializes a value stored at the given path. The value should be a pointer to a struct into which the
 // value will be deserialized. This allows you to reuse some sort of test data (e.g., TerraformOptions) from earlier
-// setup steps in later validation and teardown steps.
+// setup steps in later validation and teardown steps. Test data encrypted by SaveTestData is decrypted transparently.
 func LoadTestData(t testing.TestingT, path string, value interface{}) {
 	logger.Logf(t, "Loading test data from %s", path)
 
-	bytes, err := ioutil.ReadFile(path)
+	bytes, err := DefaultTestDataStore.Load(t, path)
+	if err != nil {
+		t.Fatalf("Failed to load value from %s: %v", path, err)
+	}
+
+	bytes, err = decryptTestData(path, bytes)
 	if err != nil {
 		t.Fatalf("Failed to load value from %s: %v", path, err)
 	}
@@ -224,9 +237,9 @@ func LoadTestData(t testing.TestingT, path string, value interface{}) {
 	}
 }
 
-// IsTestDataPresent returns true if a file exists at $path and the test data there is non-empty.
+// IsTestDataPresent returns true if test data is stored at $path and the test data there is non-empty.
 func IsTestDataPresent(t testing.TestingT, path string) bool {
-	exists, err := files.FileExistsE(path)
+	exists, err := DefaultTestDataStore.Exists(t, path)
 	if err != nil {
 		t.Fatalf("Failed to load test data from %s due to unexpected error: %v", path, err)
 	}
@@ -234,12 +247,17 @@ func IsTestDataPresent(t testing.TestingT, path string) bool {
 		return false
 	}
 
-	bytes, err := ioutil.ReadFile(path)
+	bytes, err := DefaultTestDataStore.Load(t, path)
 
 	if err != nil {
 		t.Fatalf("Failed to load test data from %s due to unexpected error: %v", path, err)
 	}
 
+	// Encrypted test data counts as present, even when the value is empty, so that checking for it doesn't need the key
+	if isEncryptedTestData(bytes) {
+		return true
+	}
+
 	if isEmptyJSON(t, bytes) {
 		return false
 	}
@@ -294,9 +312,13 @@ func isEmptyJSON(t testing.TestingT, bytes []byte) bool {
 
 // CleanupTestData cleans up the test data at the given path.
 func CleanupTestData(t testing.TestingT, path string) {
-	if files.FileExists(path) {
+	exists, err := DefaultTestDataStore.Exists(t, path)
+	if err != nil {
+		t.Fatalf("Failed to clean up test data at %s: %v", path, err)
+	}
+	if exists {
 		logger.Logf(t, "Cleaning up test data from %s", path)
-		if err := os.Remove(path); err != nil {
+		if err := DefaultTestDataStore.Delete(t, path); err != nil {
 			t.Fatalf("Failed to clean up file at %s: %v", path, err)
 		}
 	} else {
@@ -314,17 +336,8 @@ func CleanupTestDataFolder(t testing.TestingT, path string) {
 // CleanupTestDataFolderE cleans up the .test-data folder inside the given folder.
 func CleanupTestDataFolderE(t testing.TestingT, path string) error {
 	path = filepath.Join(path, ".test-data")
-	exists, err := files.FileExistsE(path)
-	if err != nil {
-		logger.Logf(t, "Failed to clean up test data folder at %s: %v", path, err)
-		return err
-	}
-
-	if !exists {
-		logger.Logf(t, "%s does not exist. Nothing to cleanup.", path)
-		return nil
-	}
-	if err := os.RemoveAll(path); err != nil {
+	logger.Logf(t, "Cleaning up test data folder %s", path)
+	if err := DefaultTestDataStore.DeleteAll(t, path); err != nil {
 		logger.Logf(t, "Failed to clean up test data folder at %s: %v", path, err)
 		return err
 	}
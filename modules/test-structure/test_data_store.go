@@ -0,0 +1,399 @@
+package test_structure
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// TestDataStore is where SaveTestData, LoadTestData and friends store the test data. The paths are the ones formatted
+// with FormatTestDataPath (e.g., <testFolder>/.test-data/TerraformOptions.json).
+type TestDataStore interface {
+	// Save stores the data at the given path, overwriting any existing data.
+	Save(t testing.TestingT, path string, data []byte) error
+	// Load returns the data stored at the given path. Returns a TestDataNotFound error if there is no data at the path.
+	Load(t testing.TestingT, path string) ([]byte, error)
+	// Exists returns true if there is data stored at the given path.
+	Exists(t testing.TestingT, path string) (bool, error)
+	// Delete removes the data stored at the given path, if any.
+	Delete(t testing.TestingT, path string) error
+	// DeleteAll removes all the data stored under the given folder, if any.
+	DeleteAll(t testing.TestingT, folder string) error
+}
+
+// DefaultTestDataStore is the TestDataStore used by SaveTestData, LoadTestData and friends. It stores the test data as
+// files in the test folder by default. To run the stages of a test in separate CI jobs, set this to a store that the
+// jobs share, such as a TarballTestDataStore that is passed between the jobs as an artifact, or an S3TestDataStore.
+var DefaultTestDataStore TestDataStore = LocalTestDataStore{}
+
+// LocalTestDataStore stores the test data as files at the given paths.
+type LocalTestDataStore struct{}
+
+// Save stores the data in a file at the given path, creating the parent folders as needed.
+func (store LocalTestDataStore) Save(t testing.TestingT, path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Load returns the contents of the file at the given path.
+func (store LocalTestDataStore) Load(t testing.TestingT, path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, TestDataNotFound{Path: path}
+	}
+	return data, err
+}
+
+// Exists returns true if a file exists at the given path.
+func (store LocalTestDataStore) Exists(t testing.TestingT, path string) (bool, error) {
+	return files.FileExistsE(path)
+}
+
+// Delete removes the file at the given path, if it exists.
+func (store LocalTestDataStore) Delete(t testing.TestingT, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DeleteAll removes the given folder and everything in it, if it exists.
+func (store LocalTestDataStore) DeleteAll(t testing.TestingT, folder string) error {
+	return os.RemoveAll(folder)
+}
+
+// TarballTestDataStore stores the test data as entries of a gzipped tarball, which can be passed between CI jobs as a
+// build artifact, so that e.g. the deploy, validate and teardown stages of a test run in separate jobs. The entries are
+// named after the paths relative to the working directory, so the jobs must run the tests from the same folder of the
+// repo, but not necessarily at the same absolute path.
+type TarballTestDataStore struct {
+	// Path of the tarball. It is created on the first save, if it doesn't exist.
+	Path string
+
+	lock sync.Mutex
+}
+
+// NewTarballTestDataStore creates a TarballTestDataStore that stores the test data in the tarball at the given path.
+func NewTarballTestDataStore(path string) *TarballTestDataStore {
+	return &TarballTestDataStore{Path: path}
+}
+
+// Save stores the data as the entry for the given path, rewriting the tarball.
+func (store *TarballTestDataStore) Save(t testing.TestingT, path string, data []byte) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	entries, err := store.readEntries()
+	if err != nil {
+		return err
+	}
+	entries[testDataKey(path)] = data
+	return store.writeEntries(entries)
+}
+
+// Load returns the data of the entry for the given path.
+func (store *TarballTestDataStore) Load(t testing.TestingT, path string) ([]byte, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	entries, err := store.readEntries()
+	if err != nil {
+		return nil, err
+	}
+	data, exists := entries[testDataKey(path)]
+	if !exists {
+		return nil, TestDataNotFound{Path: path}
+	}
+	return data, nil
+}
+
+// Exists returns true if the tarball has an entry for the given path.
+func (store *TarballTestDataStore) Exists(t testing.TestingT, path string) (bool, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	entries, err := store.readEntries()
+	if err != nil {
+		return false, err
+	}
+	_, exists := entries[testDataKey(path)]
+	return exists, nil
+}
+
+// Delete removes the entry for the given path, rewriting the tarball.
+func (store *TarballTestDataStore) Delete(t testing.TestingT, path string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	entries, err := store.readEntries()
+	if err != nil {
+		return err
+	}
+	delete(entries, testDataKey(path))
+	return store.writeEntries(entries)
+}
+
+// DeleteAll removes the entries under the given folder, rewriting the tarball.
+func (store *TarballTestDataStore) DeleteAll(t testing.TestingT, folder string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	entries, err := store.readEntries()
+	if err != nil {
+		return err
+	}
+	prefix := testDataKey(folder) + "/"
+	for key := range entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(entries, key)
+		}
+	}
+	return store.writeEntries(entries)
+}
+
+// readEntries reads all the entries of the tarball, which are small enough to keep in memory. A missing tarball has no
+// entries.
+func (store *TarballTestDataStore) readEntries() (map[string][]byte, error) {
+	entries := map[string][]byte{}
+
+	file, err := os.Open(store.Path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		entries[header.Name] = data
+	}
+}
+
+// writeEntries rewrites the tarball with the given entries. It is written to a temporary file first, so that the
+// tarball is never left half written.
+func (store *TarballTestDataStore) writeEntries(entries map[string][]byte) error {
+	keys := []string{}
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, key := range keys {
+		header := &tar.Header{Name: key, Mode: 0644, Size: int64(len(entries[key])), ModTime: time.Now()}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tarWriter.Write(entries[key]); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.Path), 0777); err != nil {
+		return err
+	}
+	tmpPath := store.Path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, buffer.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, store.Path)
+}
+
+// S3TestDataStore stores the test data as objects in an S3 bucket, so that the stages of a test can run in separate CI
+// jobs. The objects are named after the paths relative to the working directory, under the given prefix, so the jobs
+// must run the tests from the same folder of the repo, but not necessarily at the same absolute path. Use a unique
+// prefix per test run (e.g., the CI pipeline ID) to keep concurrent runs apart.
+type S3TestDataStore struct {
+	Region string
+	Bucket string
+	Prefix string
+}
+
+// NewS3TestDataStore creates an S3TestDataStore that stores the test data in the given bucket, under the given prefix.
+func NewS3TestDataStore(region string, bucket string, prefix string) *S3TestDataStore {
+	return &S3TestDataStore{Region: region, Bucket: bucket, Prefix: prefix}
+}
+
+// Save uploads the data as the object for the given path.
+func (store *S3TestDataStore) Save(t testing.TestingT, path string, data []byte) error {
+	client, err := aws.NewS3ClientE(t, store.Region)
+	if err != nil {
+		return err
+	}
+	key := store.objectKey(path)
+	logger.Logf(t, "Uploading test data to s3://%s/%s", store.Bucket, key)
+	_, err = client.PutObject(&s3.PutObjectInput{
+		Bucket: awssdk.String(store.Bucket),
+		Key:    awssdk.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+// Load downloads the object for the given path.
+func (store *S3TestDataStore) Load(t testing.TestingT, path string) ([]byte, error) {
+	client, err := aws.NewS3ClientE(t, store.Region)
+	if err != nil {
+		return nil, err
+	}
+	output, err := client.GetObject(&s3.GetObjectInput{
+		Bucket: awssdk.String(store.Bucket),
+		Key:    awssdk.String(store.objectKey(path)),
+	})
+	if isS3NotFound(err) {
+		return nil, TestDataNotFound{Path: path}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	return ioutil.ReadAll(output.Body)
+}
+
+// Exists returns true if there is an object for the given path.
+func (store *S3TestDataStore) Exists(t testing.TestingT, path string) (bool, error) {
+	client, err := aws.NewS3ClientE(t, store.Region)
+	if err != nil {
+		return false, err
+	}
+	_, err = client.HeadObject(&s3.HeadObjectInput{
+		Bucket: awssdk.String(store.Bucket),
+		Key:    awssdk.String(store.objectKey(path)),
+	})
+	if isS3NotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete removes the object for the given path, if it exists.
+func (store *S3TestDataStore) Delete(t testing.TestingT, path string) error {
+	client, err := aws.NewS3ClientE(t, store.Region)
+	if err != nil {
+		return err
+	}
+	_, err = client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: awssdk.String(store.Bucket),
+		Key:    awssdk.String(store.objectKey(path)),
+	})
+	return err
+}
+
+// DeleteAll removes all the objects under the given folder.
+func (store *S3TestDataStore) DeleteAll(t testing.TestingT, folder string) error {
+	client, err := aws.NewS3ClientE(t, store.Region)
+	if err != nil {
+		return err
+	}
+	return deleteS3Objects(client, store.Bucket, store.objectKey(folder)+"/")
+}
+
+// deleteS3Objects removes all the objects in the given bucket whose key starts with the given prefix. It stops at the
+// first object that can't be deleted, and returns that error.
+func deleteS3Objects(client s3iface.S3API, bucket string, prefix string) error {
+	var deleteErr error
+	err := client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: awssdk.String(bucket),
+		Prefix: awssdk.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			_, deleteErr = client.DeleteObject(&s3.DeleteObjectInput{Bucket: awssdk.String(bucket), Key: object.Key})
+			if deleteErr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return deleteErr
+}
+
+// objectKey returns the key of the object for the given path.
+func (store *S3TestDataStore) objectKey(path string) string {
+	key := testDataKey(path)
+	if store.Prefix == "" {
+		return key
+	}
+	return strings.TrimSuffix(store.Prefix, "/") + "/" + key
+}
+
+// isS3NotFound returns true if the given error means that the S3 object does not exist.
+func isS3NotFound(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == s3.ErrCodeNoSuchKey || awsErr.Code() == "NotFound"
+	}
+	return false
+}
+
+// testDataKey returns the name under which the test data at the given path is stored by the stores that are shared
+// between machines: the path relative to the working directory, when it is inside the working directory, and the
+// absolute path otherwise, with forward slashes and without a leading slash.
+func testDataKey(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	if workingDir, err := os.Getwd(); err == nil {
+		if relPath, err := filepath.Rel(workingDir, absPath); err == nil && !strings.HasPrefix(relPath, "..") {
+			return filepath.ToSlash(relPath)
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(absPath), "/")
+}
+
+// TestDataNotFound is an error that occurs when there is no test data stored at the given path.
+type TestDataNotFound struct {
+	Path string
+}
+
+func (err TestDataNotFound) Error() string {
+	return "No test data found at " + err.Path
+}
@@ -0,0 +1,125 @@
+package test_structure
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestDataStores(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		store func(t *testing.T) TestDataStore
+	}{
+		{"local", func(t *testing.T) TestDataStore { return LocalTestDataStore{} }},
+		{"tarball", func(t *testing.T) TestDataStore {
+			return NewTarballTestDataStore(filepath.Join(t.TempDir(), "test-data.tar.gz"))
+		}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := testCase.store(t)
+			testFolder := t.TempDir()
+			optionsPath := FormatTestDataPath(testFolder, "TerraformOptions.json")
+			stagePath := formatStageStatusPath(testFolder, "deploy")
+
+			exists, err := store.Exists(t, optionsPath)
+			require.NoError(t, err)
+			assert.False(t, exists)
+			_, err = store.Load(t, optionsPath)
+			assert.IsType(t, TestDataNotFound{}, err)
+
+			require.NoError(t, store.Save(t, optionsPath, []byte(`{"TerraformDir":"."}`)))
+			require.NoError(t, store.Save(t, stagePath, []byte(`{"Stage":"deploy"}`)))
+			data, err := store.Load(t, optionsPath)
+			require.NoError(t, err)
+			assert.Equal(t, `{"TerraformDir":"."}`, string(data))
+
+			require.NoError(t, store.Delete(t, optionsPath))
+			exists, err = store.Exists(t, optionsPath)
+			require.NoError(t, err)
+			assert.False(t, exists)
+			require.NoError(t, store.Delete(t, optionsPath))
+
+			require.NoError(t, store.DeleteAll(t, FormatTestDataPath(testFolder, stagesTestDataFolder)))
+			exists, err = store.Exists(t, stagePath)
+			require.NoError(t, err)
+			assert.False(t, exists)
+		})
+	}
+}
+
+func TestTarballTestDataStoreIsSharedBetweenStores(t *testing.T) {
+	t.Parallel()
+
+	tarballPath := filepath.Join(t.TempDir(), "test-data.tar.gz")
+	path := FormatTestDataPath("test-fixture", "TerraformOptions.json")
+
+	// Each CI job creates its own store from the tarball passed between the jobs as an artifact
+	require.NoError(t, NewTarballTestDataStore(tarballPath).Save(t, path, []byte(`"deployed"`)))
+	data, err := NewTarballTestDataStore(tarballPath).Load(t, path)
+	require.NoError(t, err)
+	assert.Equal(t, `"deployed"`, string(data))
+}
+
+func TestTestDataKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "test-fixture/.test-data/TerraformOptions.json", testDataKey("test-fixture/.test-data/TerraformOptions.json"))
+	assert.Equal(t, "test-fixture/.test-data/TerraformOptions.json", testDataKey("./test-fixture/../test-fixture/.test-data/TerraformOptions.json"))
+	assert.Equal(t, "outside/.test-data/Foo.json", testDataKey("/outside/.test-data/Foo.json"))
+}
+
+// fakeS3Client lists the given keys in a single page, and fails to delete the keys in failingKeys.
+type fakeS3Client struct {
+	s3iface.S3API
+
+	keys        []string
+	failingKeys map[string]bool
+	deletedKeys []string
+}
+
+func (client *fakeS3Client) ListObjectsV2Pages(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	page := &s3.ListObjectsV2Output{}
+	for _, key := range client.keys {
+		page.Contents = append(page.Contents, &s3.Object{Key: awssdk.String(key)})
+	}
+	fn(page, true)
+	return nil
+}
+
+func (client *fakeS3Client) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	if client.failingKeys[*input.Key] {
+		return nil, errors.New("access denied")
+	}
+	client.deletedKeys = append(client.deletedKeys, *input.Key)
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestDeleteS3Objects(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeS3Client{keys: []string{"run/.test-data/a.json", "run/.test-data/b.json"}}
+	require.NoError(t, deleteS3Objects(client, "bucket", "run/.test-data/"))
+	assert.Equal(t, client.keys, client.deletedKeys)
+
+	failingClient := &fakeS3Client{
+		keys:        []string{"run/.test-data/a.json", "run/.test-data/b.json", "run/.test-data/c.json"},
+		failingKeys: map[string]bool{"run/.test-data/b.json": true},
+	}
+	err := deleteS3Objects(failingClient, "bucket", "run/.test-data/")
+	require.EqualError(t, err, "access denied")
+	assert.Equal(t, []string{"run/.test-data/a.json"}, failingClient.deletedKeys)
+}
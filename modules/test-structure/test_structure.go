@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	go_test "testing"
 
@@ -25,6 +26,12 @@ func RunTestStage(t testing.TestingT, stageName string, stage func()) {
 	envVarName := fmt.Sprintf("%s%s", SKIP_STAGE_ENV_VAR_PREFIX, stageName)
 	if os.Getenv(envVarName) == "" {
 		logger.Logf(t, "The '%s' environment variable is not set, so executing stage '%s'.", envVarName, stageName)
+		start := time.Now()
+		// Deferred, so the duration is also logged when the stage fails the test. This is used by terratest_log_parser
+		// to report the time spent in each stage.
+		defer func() {
+			logger.Logf(t, "Finished stage '%s' in %s.", stageName, time.Since(start))
+		}()
 		stage()
 	} else {
 		logger.Logf(t, "The '%s' environment variable is set, so skipping stage '%s'.", envVarName, stageName)